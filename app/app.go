@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+
+	"github.com/Setheck/tweetstreem/util"
 )
 
 var (
@@ -37,8 +39,15 @@ const (
 func ParseFlags() RunMode {
 	verFlg := flag.Bool("v", false, "version")
 	clientFlg := flag.Bool("c", false, "client input")
+	colorFlg := flag.String("color", string(util.ColorAuto), "when to color output: auto, always or never")
 	flag.Parse()
 
+	colorMode, err := util.ParseColorMode(*colorFlg)
+	if err != nil {
+		fmt.Println(err)
+	}
+	util.SetColorMode(colorMode)
+
 	switch {
 	case *verFlg:
 		return version
@@ -71,15 +80,27 @@ func Run() int {
 	fmt.Printf("| auto-update | %s |\n",
 		ts.TwitterConfiguration.PollTimeDuration())
 
-	if err := ts.StartSubsystems(); err != nil {
+	// the terminal is restored from the line editor before exiting, log.Fatal skips deferred calls
+	defer func() {
+		if r := recover(); r != nil {
+			ts.stopLineEditor()
+			panic(r)
+		}
+	}()
+	fatal := func(err error) {
+		ts.stopLineEditor()
 		log.Fatal(err)
 	}
 
+	if err := ts.StartSubsystems(); err != nil {
+		fatal(err)
+	}
+
 	ts.WaitForDone()
 
 	// Shutdown Sequence
 	if err := ts.SaveConfig(); err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
 
 	fmt.Println("\n'till next time o/ ")
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+)
+
+// every tweet shown is appended to the archive, one json tweet per line
+var archiveFile = ".tweetstreem_archive.jsonl"
+
+// DefaultFindLimit is the number of matches shown by find.
+const DefaultFindLimit = 20
+
+// maxArchiveIndex is the number of the most recent archived tweets that are indexed, older tweets are kept
+// in the archive file but are no longer searched.
+var maxArchiveIndex = 100_000
+
+// Archive is an append-only local store of tweets, persisted to disk as json lines.
+// The searchable fields of the most recent tweets are indexed in memory when the archive is first used,
+// so a search only reads the matching tweets from disk.
+type Archive struct {
+	path    string
+	loaded  bool
+	entries []archiveEntry
+	ids     map[string]bool
+	lock    sync.Mutex
+}
+
+// archiveEntry is the index of a single archived tweet.
+type archiveEntry struct {
+	id          string
+	offset      int64
+	length      int
+	screenNames []string // the author, and the retweeter of a retweet
+	text        string   // lower case
+	hasLink     bool
+	createdAt   time.Time
+}
+
+// NewArchive creates a new instance that persists to the given path.
+func NewArchive(path string) *Archive {
+	return &Archive{path: path}
+}
+
+// Add appends the tweets that are not already archived.
+func (a *Archive) Add(tweets ...*twitter.Tweet) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if err := a.load(); err != nil {
+		return err
+	}
+
+	var f *os.File
+	for _, tw := range tweets {
+		if tw.IDStr == "" || a.ids[tw.IDStr] {
+			continue
+		}
+		data, err := json.Marshal(tw)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			if f, err = os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+				return fmt.Errorf("failed to open archive: %w", err)
+			}
+			defer f.Close()
+		}
+		offset, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		a.index(tw, offset, len(data))
+	}
+	return nil
+}
+
+// Find returns up to limit of the archived tweets that match the query, newest first.
+func (a *Archive) Find(query ArchiveQuery, limit int) ([]*twitter.Tweet, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+
+	var matches []archiveEntry
+	for i := len(a.entries) - 1; i >= 0 && len(matches) < limit; i-- {
+		if query.matches(a.entries[i]) {
+			matches = append(matches, a.entries[i])
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer f.Close()
+	tweets := make([]*twitter.Tweet, 0, len(matches))
+	for _, entry := range matches {
+		data := make([]byte, entry.length)
+		if _, err := f.ReadAt(data, entry.offset); err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		tw := new(twitter.Tweet)
+		if err := json.Unmarshal(data, tw); err != nil {
+			return nil, fmt.Errorf("failed to parse archive %q: %w", a.path, err)
+		}
+		tweets = append(tweets, tw)
+	}
+	return tweets, nil
+}
+
+// Len returns the number of archived tweets.
+func (a *Archive) Len() (int, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if err := a.load(); err != nil {
+		return 0, err
+	}
+	return len(a.entries), nil
+}
+
+// load indexes the tweets already in the archive.
+func (a *Archive) load() error {
+	if a.loaded {
+		return nil
+	}
+	a.ids = make(map[string]bool)
+	f, err := os.Open(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		a.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		data, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				// a partial line was not fully written, it is removed so the next tweet starts on its own line
+				if err := os.Truncate(a.path, offset); err != nil {
+					return fmt.Errorf("failed to repair archive: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		// a line that cannot be parsed is left out of the index
+		tw := new(twitter.Tweet)
+		if err := json.Unmarshal(data, tw); err == nil {
+			a.index(tw, offset, len(data)-1)
+		}
+		offset += int64(len(data))
+	}
+	a.loaded = true
+	return nil
+}
+
+func (a *Archive) index(tw *twitter.Tweet, offset int64, length int) {
+	orig := tw.Original()
+	text := orig.Text
+	if orig.FullText != "" {
+		text = orig.FullText
+	}
+	screenNames := []string{strings.ToLower(orig.User.ScreenName)}
+	if orig != tw {
+		screenNames = append(screenNames, strings.ToLower(tw.User.ScreenName))
+	}
+	createdAt, _ := twitter.ParseCreatedAt(tw.CreatedAt)
+	a.entries = append(a.entries, archiveEntry{
+		id:          tw.IDStr,
+		offset:      offset,
+		length:      length,
+		screenNames: screenNames,
+		text:        strings.ToLower(html.UnescapeString(text)),
+		hasLink:     len(orig.Links()) > 0,
+		createdAt:   createdAt,
+	})
+	a.ids[tw.IDStr] = true
+	if len(a.entries) > maxArchiveIndex+maxArchiveIndex/10 {
+		// the oldest entries are dropped in batches, rather than one at a time
+		drop := len(a.entries) - maxArchiveIndex
+		for _, entry := range a.entries[:drop] {
+			delete(a.ids, entry.id)
+		}
+		a.entries = append([]archiveEntry(nil), a.entries[drop:]...)
+	}
+}
+
+// ArchiveQuery selects archived tweets, every condition that is set must match.
+type ArchiveQuery struct {
+	Terms   []string  // words in the tweet text, case insensitive
+	From    string    // the author's screen name, or the retweeter's
+	HasLink bool      // tweets with a link or media
+	Since   time.Time // tweets created at or after
+}
+
+// ParseArchiveQuery parses search terms, where a phrase (quoted on the command line) is a single term, and the filters
+// 'from:<screen_name>', 'has:link' and 'since:<2006-01-02|duration>' eg: 'since:2d' for the last two days.
+func ParseArchiveQuery(args ...string) (ArchiveQuery, error) {
+	var query ArchiveQuery
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t") {
+			query.Terms = append(query.Terms, strings.ToLower(arg))
+			continue
+		}
+		key, value, _ := strings.Cut(arg, ":")
+		switch strings.ToLower(key) {
+		case "":
+			continue
+		case "from":
+			query.From = strings.ToLower(strings.TrimPrefix(value, "@"))
+		case "has":
+			if strings.ToLower(value) != "link" {
+				return query, fmt.Errorf("invalid filter %q, expected has:link", arg)
+			}
+			query.HasLink = true
+		case "since":
+			since, err := parseSince(value)
+			if err != nil {
+				return query, err
+			}
+			query.Since = since
+		default:
+			query.Terms = append(query.Terms, strings.ToLower(arg))
+		}
+	}
+	if query.isEmpty() {
+		return query, fmt.Errorf("search terms or filters are required, eg: 'find golang from:someone'")
+	}
+	return query, nil
+}
+
+// parseSince parses a date, or a duration before now.
+func parseSince(s string) (time.Time, error) {
+	if tm, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return tm, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid since:%s, expected a date eg: 2006-01-02 or a duration eg: 2d", s)
+	}
+	return timeNow().Add(-d), nil
+}
+
+func (q ArchiveQuery) isEmpty() bool {
+	return len(q.Terms) == 0 && q.From == "" && !q.HasLink && q.Since.IsZero()
+}
+
+func (q ArchiveQuery) matches(entry archiveEntry) bool {
+	if q.HasLink && !entry.hasLink {
+		return false
+	}
+	if !q.Since.IsZero() && entry.createdAt.Before(q.Since) {
+		return false
+	}
+	if q.From != "" {
+		found := false
+		for _, screenName := range entry.screenNames {
+			found = found || screenName == q.From
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, term := range q.Terms {
+		if !strings.Contains(entry.text, term) {
+			return false
+		}
+	}
+	return true
+}
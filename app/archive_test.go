@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+func archiveTweets(now time.Time) []*twitter.Tweet {
+	createdAt := func(d time.Duration) string { return now.Add(-d).Format(twitter.CreatedAtTimeLayout) }
+	return []*twitter.Tweet{
+		{IDStr: "1", CreatedAt: createdAt(72 * time.Hour), Text: "Learning Go today", User: twitter.User{ScreenName: "gopher"}},
+		{IDStr: "2", CreatedAt: createdAt(2 * time.Hour), FullText: "read this &amp; that https://t.co/x",
+			User:     twitter.User{ScreenName: "reader"},
+			Entities: twitter.Entities{Urls: []twitter.URL{{ExpandedURL: "https://example.com"}}}},
+		{IDStr: "3", CreatedAt: createdAt(time.Hour), User: twitter.User{ScreenName: "fan"},
+			ReTweetedStatus: &twitter.Tweet{IDStr: "4", Text: "go go go", User: twitter.User{ScreenName: "gopher"}}},
+	}
+}
+
+func TestArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), archiveFile)
+	archive := NewArchive(path)
+	tweets := archiveTweets(time.Now())
+
+	n, err := archive.Len()
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	found, err := archive.Find(ArchiveQuery{Terms: []string{"go"}}, DefaultFindLimit)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.NoError(t, archive.Add(tweets[:2]...))
+	assert.NoError(t, archive.Add(tweets...))
+	assert.NoError(t, archive.Add(&twitter.Tweet{Text: "no id"}))
+	n, err = archive.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "tweets are only archived once")
+
+	// a fresh store indexes the archive on disk
+	reloaded := NewArchive(path)
+	found, err = reloaded.Find(ArchiveQuery{Terms: []string{"go"}}, DefaultFindLimit)
+	assert.NoError(t, err)
+	assert.Equal(t, []*twitter.Tweet{tweets[2], tweets[0]}, found, "newest first")
+
+	found, err = reloaded.Find(ArchiveQuery{Terms: []string{"go"}}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*twitter.Tweet{tweets[2]}, found)
+}
+
+func TestArchive_Find(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	now := time.Date(2020, 3, 25, 14, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	archive := NewArchive(filepath.Join(t.TempDir(), archiveFile))
+	tweets := archiveTweets(now)
+	assert.NoError(t, archive.Add(tweets...))
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"term", []string{"GO"}, []string{"3", "1"}},
+		{"terms", []string{"learning", "go"}, []string{"1"}},
+		{"phrase", []string{"this & that"}, []string{"2"}},
+		{"from", []string{"from:@Gopher"}, []string{"3", "1"}},
+		{"from retweeter", []string{"from:fan"}, []string{"3"}},
+		{"has link", []string{"has:link"}, []string{"2"}},
+		{"since duration", []string{"since:1d"}, []string{"3", "2"}},
+		{"since date", []string{"since:2020-03-24"}, []string{"3", "2"}},
+		{"combined", []string{"go", "from:gopher", "since:1d"}, []string{"3"}},
+		{"no match", []string{"rust"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := ParseArchiveQuery(test.args...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			found, err := archive.Find(query, DefaultFindLimit)
+			assert.NoError(t, err)
+			var ids []string
+			for _, tw := range found {
+				ids = append(ids, tw.IDStr)
+			}
+			assert.Equal(t, test.want, ids)
+		})
+	}
+}
+
+func TestParseArchiveQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"empty", nil, "search terms or filters are required, eg: 'find golang from:someone'"},
+		{"blank", []string{"", ""}, "search terms or filters are required, eg: 'find golang from:someone'"},
+		{"has", []string{"has:video"}, `invalid filter "has:video", expected has:link`},
+		{"since", []string{"since:soon"}, "invalid since:soon, expected a date eg: 2006-01-02 or a duration eg: 2d"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseArchiveQuery(test.args...)
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestArchive_LoadFailure(t *testing.T) {
+	path := t.TempDir() // a directory cannot be read as an archive
+
+	archive := NewArchive(path)
+	_, err := archive.Find(ArchiveQuery{Terms: []string{"go"}}, DefaultFindLimit)
+	assert.Error(t, err)
+	assert.Error(t, archive.Add(&twitter.Tweet{IDStr: "1"}))
+}
+
+func TestArchive_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), archiveFile)
+	assert.NoError(t, os.WriteFile(path, []byte("garbage\n"+`{"id_str":"1","text":"go"}`+"\n"), 0600))
+
+	archive := NewArchive(path)
+	found, err := archive.Find(ArchiveQuery{Terms: []string{"go"}}, DefaultFindLimit)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "1", found[0].IDStr)
+	}
+	assert.NoError(t, archive.Add(&twitter.Tweet{IDStr: "2"}))
+}
+
+func TestArchive_PartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), archiveFile)
+	assert.NoError(t, os.WriteFile(path, []byte(`{"id_str":"1","text":"go"}`+"\n"+`{"id_str":"2","te`), 0600))
+
+	archive := NewArchive(path)
+	n, err := archive.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// the partial line is removed, so the next tweet is not written onto it
+	assert.NoError(t, archive.Add(&twitter.Tweet{IDStr: "3", Text: "go"}))
+	reloaded := NewArchive(path)
+	found, err := reloaded.Find(ArchiveQuery{Terms: []string{"go"}}, DefaultFindLimit)
+	assert.NoError(t, err)
+	var ids []string
+	for _, tw := range found {
+		ids = append(ids, tw.IDStr)
+	}
+	assert.Equal(t, []string{"3", "1"}, ids)
+}
+
+func TestArchive_IndexLimit(t *testing.T) {
+	limitSave := maxArchiveIndex
+	defer func() { maxArchiveIndex = limitSave }()
+	maxArchiveIndex = 10
+
+	archive := NewArchive(filepath.Join(t.TempDir(), archiveFile))
+	for i := 1; i <= 12; i++ {
+		assert.NoError(t, archive.Add(&twitter.Tweet{IDStr: strconv.Itoa(i), Text: "go"}))
+	}
+	n, err := archive.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n, "the oldest tweets are no longer indexed")
+	found, err := archive.Find(ArchiveQuery{Terms: []string{"go"}}, 20)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 10) {
+		assert.Equal(t, "12", found[0].IDStr)
+		assert.Equal(t, "3", found[9].IDStr)
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Find(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	tw.archive = NewArchive(filepath.Join(t.TempDir(), archiveFile))
+	tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	tweets := archiveTweets(time.Now())
+	assert.NoError(t, tw.archive.Add(tweets...))
+
+	assert.NoError(t, tw.ProcessCommand("find go"))
+	verifyPrint(t, tw, "1:gopher\n")
+	verifyPrint(t, tw, "2:gopher\n")
+	found, err := tw.findTweet("2")
+	assert.NoError(t, err)
+	assert.Equal(t, tweets[2].ReTweetedStatus, found, "matches are added to the history")
+
+	assert.NoError(t, tw.ProcessCommand("find rust"))
+	verifyPrint(t, tw, "no matches\n")
+
+	assert.EqualError(t, tw.ProcessCommand("find"), "search terms or filters are required, eg: 'find golang from:someone'")
+}
+
+func TestTweetStreem_PrintTweets_Archive(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	tw.archive = NewArchive(filepath.Join(t.TempDir(), archiveFile))
+	tw.TweetTemplate = "{{ .Id }}\n"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	tweets := archiveTweets(time.Now())
+
+	tw.PrintTweets(tweets[:1])
+	verifyPrint(t, tw, "1\n")
+	n, err := tw.archive.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	tw.EnableArchive = false
+	tw.PrintTweets(tweets[1:2])
+	verifyPrint(t, tw, "2\n")
+	n, err = tw.archive.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,376 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+)
+
+// the actions an automation rule can perform
+const (
+	ActionLike     = "like"
+	ActionRetweet  = "retweet"
+	ActionReply    = "reply"    // reply with the rule's reply template
+	ActionBookmark = "bookmark" // bookmark, like the bookmark command
+	ActionWebhook  = "webhook"  // post the tweet as json to the rule's webhook url
+)
+
+var automationActions = []string{ActionLike, ActionRetweet, ActionReply, ActionBookmark, ActionWebhook}
+
+// DefaultAutomationMaxPerHour is the number of tweets a rule acts on in an hour, when it does not set its own cap.
+const DefaultAutomationMaxPerHour = 10
+
+// every automated action is appended to the audit log, in the config path
+var automationLogFile = ".tweetstreem_automation.log"
+
+// test point
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// AutomationMatch is the kind of tweet an automation rule acts on.
+type AutomationMatch string
+
+// the kinds of automation rule
+const (
+	AutomationText    AutomationMatch = "text"    // the text contains the value, case insensitive
+	AutomationAuthor  AutomationMatch = "author"  // by the user
+	AutomationMention AutomationMatch = "mention" // mentions you
+	AutomationHashtag AutomationMatch = "hashtag" // has the hashtag
+)
+
+var automationMatches = []string{
+	string(AutomationText), string(AutomationAuthor), string(AutomationMention), string(AutomationHashtag),
+}
+
+// AutomationConfig configures the rules that act on tweets as they are streamed.
+type AutomationConfig struct {
+	Enabled bool              `json:"enabled"` // the kill switch, no actions are performed while disabled
+	DryRun  bool              `json:"dryRun"`  // audit the actions without performing them
+	Rules   []*AutomationRule `json:"rules"`
+}
+
+// DefaultAutomationConfig is enabled in dry run, so a new rule is audited until dry run is turned off.
+func DefaultAutomationConfig() AutomationConfig {
+	return AutomationConfig{Enabled: true, DryRun: true}
+}
+
+// AutomationRule performs its actions on the streamed tweets that match it.
+type AutomationRule struct {
+	Name       string          `json:"name"`
+	Match      AutomationMatch `json:"match"`           // one of text, author, mention or hashtag
+	Value      string          `json:"value,omitempty"` // the text, screen name or hashtag, not used by mention
+	Actions    []string        `json:"actions"`
+	Reply      string          `json:"reply,omitempty"`      // the reply template, eg: 'thanks for sharing @{{ .ScreenName }}!'
+	Webhook    string          `json:"webhook,omitempty"`    // the url tweets are posted to
+	MaxPerHour int             `json:"maxPerHour,omitempty"` // defaults to DefaultAutomationMaxPerHour
+
+	replyTemplate *template.Template
+	fired         []time.Time // when the rule acted, in the last hour
+	capped        bool        // the cap was reported, it is reported once until the rule acts again
+}
+
+// validate checks the match, actions, reply template and webhook url of the rule.
+func (r *AutomationRule) validate() error {
+	r.Match = AutomationMatch(strings.ToLower(string(r.Match)))
+	switch r.Match {
+	case AutomationMention:
+	case AutomationText, AutomationAuthor, AutomationHashtag:
+		if r.Value == "" {
+			return fmt.Errorf("the %s automation rule requires a value", r.Match)
+		}
+	default:
+		return fmt.Errorf("invalid automation rule %q, expected one of %s", r.Match, strings.Join(automationMatches, ", "))
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("automation %q has no actions, expected any of %s", r.Name, strings.Join(automationActions, ", "))
+	}
+	if r.MaxPerHour < 0 {
+		return fmt.Errorf("automation %q has an invalid maxPerHour %d", r.Name, r.MaxPerHour)
+	}
+	for i, action := range r.Actions {
+		r.Actions[i] = strings.ToLower(action)
+		switch r.Actions[i] {
+		case ActionLike, ActionRetweet, ActionBookmark:
+		case ActionReply:
+			if r.Reply == "" {
+				return fmt.Errorf("automation %q has no reply template", r.Name)
+			}
+			tpl, err := template.New(r.Name).Parse(r.Reply)
+			if err != nil {
+				return fmt.Errorf("automation %q has an invalid reply template: %w", r.Name, err)
+			}
+			r.replyTemplate = tpl
+		case ActionWebhook:
+			u, err := url.Parse(r.Webhook)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("automation %q has an invalid webhook url %q", r.Name, r.Webhook)
+			}
+		default:
+			return fmt.Errorf("automation %q has an invalid action %q, expected one of %s",
+				r.Name, action, strings.Join(automationActions, ", "))
+		}
+	}
+	return nil
+}
+
+// allow records that the rule acts at now, unless it has reached its cap in the last hour.
+func (r *AutomationRule) allow(now time.Time) bool {
+	recent := r.fired[:0]
+	for _, tm := range r.fired {
+		if now.Sub(tm) < time.Hour {
+			recent = append(recent, tm)
+		}
+	}
+	r.fired = recent
+	if len(r.fired) >= r.maxPerHour() {
+		return false
+	}
+	r.fired = append(r.fired, now)
+	return true
+}
+
+func (r *AutomationRule) maxPerHour() int {
+	if r.MaxPerHour == 0 {
+		return DefaultAutomationMaxPerHour
+	}
+	return r.MaxPerHour
+}
+
+// validateAutomations validates the configured automation rules.
+func (t *TweetStreem) validateAutomations() error {
+	for _, r := range t.Automation.Rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// automationState returns the kill switch and dry run settings.
+func (t *TweetStreem) automationState() (enabled, dryRun bool) {
+	t.automationLock.Lock()
+	defer t.automationLock.Unlock()
+	return t.Automation.Enabled, t.Automation.DryRun
+}
+
+// automate performs the actions of each matching rule on the streamed tweets posted since the given time,
+// oldest first. the first poll has no since_id, so older tweets are skipped rather than acted on at startup.
+// your own tweets and retweets are skipped, so rules cannot act on their own actions.
+func (t *TweetStreem) automate(tweets []*twitter.Tweet, since time.Time) {
+	screenName := t.TemplateOutputConfig.ScreenName
+	for i := len(tweets) - 1; i >= 0; i-- {
+		tw := tweets[i]
+		if created, err := twitter.ParseCreatedAt(tw.CreatedAt); err != nil || created.Before(since) {
+			continue
+		}
+		if screenName != "" && (strings.EqualFold(tw.User.ScreenName, screenName) ||
+			strings.EqualFold(tw.Original().User.ScreenName, screenName)) {
+			continue
+		}
+		for _, r := range t.Automation.Rules {
+			if !r.Matches(tw, screenName) {
+				continue
+			}
+			if enabled, _ := t.automationState(); !enabled {
+				return
+			}
+			t.automationLock.Lock()
+			allowed := r.allow(timeNow())
+			report := !allowed && !r.capped
+			r.capped = !allowed
+			t.automationLock.Unlock()
+			if report {
+				t.audit(r, "-", tw, fmt.Sprintf("skipped, the rule has reached its cap of %s per hour, "+
+					"matching tweets are skipped until it acts again", plural("action", "actions", r.maxPerHour())))
+			}
+			if !allowed {
+				continue
+			}
+			for _, action := range r.Actions {
+				// checked before every action, so the kill switch stops a rule part way through
+				enabled, dryRun := t.automationState()
+				if !enabled {
+					return
+				}
+				result := "ok"
+				if dryRun {
+					result = "dry run"
+				} else if err := t.automationAction(r, action, tw.Original()); err != nil {
+					result = fmt.Sprint("error: ", err)
+				}
+				t.audit(r, action, tw, result)
+			}
+		}
+	}
+}
+
+// Matches reports whether the rule acts on the tweet, screenName is the authenticated user.
+func (r *AutomationRule) Matches(tw *twitter.Tweet, screenName string) bool {
+	orig := tw.Original()
+	switch r.Match {
+	case AutomationText:
+		return tweetContains(orig, r.Value)
+	case AutomationAuthor:
+		return tweetByAuthor(orig, r.Value)
+	case AutomationMention:
+		return tweetMentions(orig, screenName)
+	case AutomationHashtag:
+		return tweetHasHashtag(orig, r.Value)
+	}
+	return false
+}
+
+func (t *TweetStreem) automationAction(r *AutomationRule, action string, tw *twitter.Tweet) error {
+	switch action {
+	case ActionLike:
+		return t.twitter.Like(tw, twitter.NewURLValues())
+	case ActionRetweet:
+		return t.twitter.ReTweet(tw, twitter.NewURLValues())
+	case ActionReply:
+		buf := new(bytes.Buffer)
+		if err := r.replyTemplate.Execute(buf, tw.TemplateOutput(twitter.OutputConfig{})); err != nil {
+			return err
+		}
+		msg := strings.TrimSpace(buf.String())
+		// a reply must mention the author to be threaded
+		if !strings.Contains(strings.ToLower(msg), "@"+strings.ToLower(tw.User.ScreenName)) {
+			msg = fmt.Sprintf("@%s %s", tw.User.ScreenName, msg)
+		}
+		conf := twitter.NewURLValues()
+		conf.Set("in_reply_to_status_id", tw.IDStr)
+		_, err := t.twitter.UpdateStatus(msg, conf)
+		return err
+	case ActionBookmark:
+		_, err := t.addBookmark(tw)
+		return err
+	case ActionWebhook:
+		return postWebhook(r.Webhook, r.Name, tw)
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+// postWebhook posts the rule name and tweet as json to the url.
+func postWebhook(u, rule string, tw *twitter.Tweet) error {
+	data, err := json.Marshal(struct {
+		Rule  string         `json:"rule"`
+		Tweet *twitter.Tweet `json:"tweet"`
+	}{rule, tw})
+	if err != nil {
+		return err
+	}
+	resp, err := webhookClient.Post(u, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// audit prints the action and appends it to the audit log.
+func (t *TweetStreem) audit(r *AutomationRule, action string, tw *twitter.Tweet, result string) {
+	entry := fmt.Sprintf("rule=%q action=%s tweet=%s user=@%s result=%s",
+		r.Name, action, tw.Original().IDStr, tw.Original().User.ScreenName, result)
+	t.println("automation:", entry)
+
+	line := fmt.Sprintln(timeNow().Format(time.RFC3339), entry)
+	f, err := os.OpenFile(filepath.Join(configPath, automationLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.println("Error: failed to write automation log:", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		t.println("Error: failed to write automation log:", err)
+	}
+}
+
+func (t *TweetStreem) commandAutomation(args ...string) error {
+	if len(args) == 0 || args[0] == "" {
+		t.print(t.automationStatus())
+		return nil
+	}
+	switch cmd := strings.ToLower(args[0]); cmd {
+	case "ls", "list":
+		t.print(t.automationStatus())
+	case "on", "off":
+		t.setAutomation(func(c *AutomationConfig) { c.Enabled = cmd == "on" })
+		t.println("automations", cmd)
+	case "dryrun":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			return fmt.Errorf("expected 'auto dryrun on' or 'auto dryrun off'")
+		}
+		t.setAutomation(func(c *AutomationConfig) { c.DryRun = args[1] == "on" })
+		t.println("automation dry run", args[1])
+	case "log":
+		n := 10
+		if len(args) > 1 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of entries: %s", args[1])
+			}
+		}
+		return t.automationLog(n)
+	default:
+		return fmt.Errorf("unknown auto command %q, expected ls, on, off, dryrun or log", args[0])
+	}
+	return nil
+}
+
+func (t *TweetStreem) setAutomation(set func(c *AutomationConfig)) {
+	t.automationLock.Lock()
+	defer t.automationLock.Unlock()
+	set(&t.Automation)
+}
+
+// automationStatus lists the kill switch, dry run and rules, with the number of times each acted in the last hour.
+func (t *TweetStreem) automationStatus() string {
+	t.automationLock.Lock()
+	defer t.automationLock.Unlock()
+	onOff := map[bool]string{true: "on", false: "off"}
+	out := fmt.Sprintf("automations: %s, dry run: %s\n", onOff[t.Automation.Enabled], onOff[t.Automation.DryRun])
+	now := timeNow()
+	for i, r := range t.Automation.Rules {
+		recent := 0
+		for _, tm := range r.fired {
+			if now.Sub(tm) < time.Hour {
+				recent++
+			}
+		}
+		match := string(r.Match)
+		if r.Value != "" {
+			match += " " + r.Value
+		}
+		out += fmt.Sprintf("%d: %s (%s) %s - %d/%d this hour\n", i, r.Name, match, strings.Join(r.Actions, ", "), recent, r.maxPerHour())
+	}
+	return out
+}
+
+// automationLog prints the last n entries of the audit log.
+func (t *TweetStreem) automationLog(n int) error {
+	data, err := os.ReadFile(filepath.Join(configPath, automationLogFile))
+	if os.IsNotExist(err) {
+		t.println("no automation log")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read automation log: %w", err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	t.print(strings.Join(lines, "") + "\n")
+	return nil
+}
@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/twitter/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAutomationRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    AutomationRule
+		wantErr string
+	}{
+		{"valid", AutomationRule{Name: "partners", Match: "Author", Value: "partner",
+			Actions: []string{"Like", "retweet", "bookmark"}}, ""},
+		{"reply", AutomationRule{Name: "thanks", Match: "mention", Actions: []string{"reply"}, Reply: "thanks!"}, ""},
+		{"webhook", AutomationRule{Name: "hook", Match: "hashtag", Value: "go", Actions: []string{"webhook"},
+			Webhook: "https://example.com/hook"}, ""},
+		{"match", AutomationRule{Name: "bad", Match: "user", Value: "me", Actions: []string{"like"}},
+			`invalid automation rule "user", expected one of text, author, mention, hashtag`},
+		{"no actions", AutomationRule{Name: "idle", Match: "mention"},
+			`automation "idle" has no actions, expected any of like, retweet, reply, bookmark, webhook`},
+		{"action", AutomationRule{Name: "bad", Match: "mention", Actions: []string{"follow"}},
+			`automation "bad" has an invalid action "follow", expected one of like, retweet, reply, bookmark, webhook`},
+		{"no reply", AutomationRule{Name: "bad", Match: "mention", Actions: []string{"reply"}},
+			`automation "bad" has no reply template`},
+		{"reply template", AutomationRule{Name: "bad", Match: "mention", Actions: []string{"reply"}, Reply: "{{ .Oops"},
+			`automation "bad" has an invalid reply template: template: bad:1: unclosed action`},
+		{"webhook url", AutomationRule{Name: "bad", Match: "mention", Actions: []string{"webhook"}, Webhook: "example.com"},
+			`automation "bad" has an invalid webhook url "example.com"`},
+		{"max per hour", AutomationRule{Name: "bad", Match: "mention", Actions: []string{"like"}, MaxPerHour: -1},
+			`automation "bad" has an invalid maxPerHour -1`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rule.validate()
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAutomationRule_Allow(t *testing.T) {
+	now := time.Date(2020, 3, 25, 14, 30, 0, 0, time.UTC)
+	rule := &AutomationRule{MaxPerHour: 2}
+	assert.True(t, rule.allow(now))
+	assert.True(t, rule.allow(now.Add(10*time.Minute)))
+	assert.False(t, rule.allow(now.Add(20*time.Minute)))
+	assert.True(t, rule.allow(now.Add(time.Hour)), "the first action is over an hour old")
+	assert.False(t, rule.allow(now.Add(time.Hour+time.Minute)))
+
+	rule = &AutomationRule{}
+	for i := 0; i < DefaultAutomationMaxPerHour; i++ {
+		assert.True(t, rule.allow(now))
+	}
+	assert.False(t, rule.allow(now))
+}
+
+// runAutomate automates the tweets as if they were just streamed, and returns what was printed.
+func runAutomate(tw *TweetStreem, tweets ...*twitter.Tweet) []string {
+	now := timeNow()
+	for _, t := range tweets {
+		if t.CreatedAt == "" {
+			t.CreatedAt = now.Format(twitter.CreatedAtTimeLayout)
+		}
+	}
+	return collectPrints(tw, func() { tw.automate(tweets, now) })
+}
+
+// collectPrints runs fn, and returns what was printed.
+func collectPrints(tw *TweetStreem, fn func()) []string {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+	var printed []string
+	for {
+		select {
+		case msg := <-tw.printCh:
+			printed = append(printed, msg)
+		case <-done:
+			for {
+				select {
+				case msg := <-tw.printCh:
+					printed = append(printed, msg)
+				default:
+					return printed
+				}
+			}
+		}
+	}
+}
+
+func newAutomationTweetStreem(t *testing.T, rules ...*AutomationRule) *TweetStreem {
+	pathSave := configPath
+	t.Cleanup(func() { configPath = pathSave })
+	configPath = t.TempDir()
+
+	nowSave := timeNow
+	t.Cleanup(func() { timeNow = nowSave })
+	now := time.Date(2020, 3, 25, 14, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	tw := NewTweetStreem(context.TODO())
+	tw.bookmarks = NewBookmarks(filepath.Join(configPath, bookmarksFile))
+	tw.TemplateOutputConfig.ScreenName = "me"
+	assert.True(t, tw.Automation.DryRun, "automations start in dry run")
+	tw.Automation.DryRun = false
+	tw.Automation.Rules = rules
+	for _, r := range rules {
+		assert.NoError(t, r.validate())
+	}
+	return tw
+}
+
+func TestTweetStreem_Automate(t *testing.T) {
+	var hooked []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		hooked, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	partner := &twitter.Tweet{IDStr: "100", Text: "our launch", User: twitter.User{ScreenName: "partner"}}
+	rule := &AutomationRule{
+		Name:    "partners",
+		Match:   AutomationAuthor,
+		Value:   "partner",
+		Actions: []string{ActionLike, ActionRetweet, ActionReply, ActionBookmark, ActionWebhook},
+		Reply:   "congrats on {{ .TweetText }}",
+		Webhook: srv.URL,
+	}
+	twitterMock := new(mocks.Client)
+	twitterMock.On("Like", partner, mock.Anything).Return(nil)
+	twitterMock.On("ReTweet", partner, mock.Anything).Return(assert.AnError)
+	twitterMock.On("Bookmark", partner).Return(nil)
+	twitterMock.On("UpdateStatus", "@partner congrats on our launch", mock.MatchedBy(func(v url.Values) bool {
+		return v.Get("in_reply_to_status_id") == "100"
+	})).Return(&twitter.Tweet{IDStr: "101"}, nil)
+
+	tw := newAutomationTweetStreem(t, rule)
+	tw.twitter = twitterMock
+	printed := runAutomate(tw,
+		&twitter.Tweet{IDStr: "3", User: twitter.User{ScreenName: "other"}},
+		&twitter.Tweet{IDStr: "2", User: twitter.User{ScreenName: "me"}, ReTweetedStatus: partner},
+		partner,
+	)
+	assert.Equal(t, []string{
+		"automation: rule=\"partners\" action=like tweet=100 user=@partner result=ok\n",
+		"automation: rule=\"partners\" action=retweet tweet=100 user=@partner result=error: " + assert.AnError.Error() + "\n",
+		"automation: rule=\"partners\" action=reply tweet=100 user=@partner result=ok\n",
+		"automation: rule=\"partners\" action=bookmark tweet=100 user=@partner result=ok\n",
+		"automation: rule=\"partners\" action=webhook tweet=100 user=@partner result=ok\n",
+	}, printed, "your own retweet of the partner tweet is skipped")
+	twitterMock.AssertExpectations(t)
+
+	bookmarked, err := tw.bookmarks.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*twitter.Tweet{partner}, bookmarked)
+
+	var payload struct {
+		Rule  string         `json:"rule"`
+		Tweet *twitter.Tweet `json:"tweet"`
+	}
+	assert.NoError(t, json.Unmarshal(hooked, &payload))
+	assert.Equal(t, "partners", payload.Rule)
+	assert.Equal(t, partner, payload.Tweet)
+
+	log, err := os.ReadFile(filepath.Join(configPath, automationLogFile))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, `2020-03-25T14:30:00Z rule="partners" action=like tweet=100 user=@partner result=ok`, lines[0])
+}
+
+func TestTweetStreem_Automate_DryRun(t *testing.T) {
+	tw := newAutomationTweetStreem(t, &AutomationRule{Name: "likes", Match: AutomationText, Value: "go", Actions: []string{ActionLike}})
+	tw.Automation.DryRun = true
+	twitterMock := new(mocks.Client)
+	tw.twitter = twitterMock
+
+	printed := runAutomate(tw, &twitter.Tweet{IDStr: "1", Text: "go", User: twitter.User{ScreenName: "gopher"}})
+	assert.Equal(t, []string{"automation: rule=\"likes\" action=like tweet=1 user=@gopher result=dry run\n"}, printed)
+	twitterMock.AssertNotCalled(t, "Like", mock.Anything, mock.Anything)
+}
+
+func TestTweetStreem_Automate_KillSwitch(t *testing.T) {
+	tw := newAutomationTweetStreem(t, &AutomationRule{Name: "likes", Match: AutomationText, Value: "go", Actions: []string{ActionLike}})
+	tw.Automation.Enabled = false
+	twitterMock := new(mocks.Client)
+	tw.twitter = twitterMock
+
+	assert.Empty(t, runAutomate(tw, &twitter.Tweet{IDStr: "1", Text: "go", User: twitter.User{ScreenName: "gopher"}}))
+	twitterMock.AssertNotCalled(t, "Like", mock.Anything, mock.Anything)
+	_, err := os.Stat(filepath.Join(configPath, automationLogFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTweetStreem_Automate_RateCap(t *testing.T) {
+	tw := newAutomationTweetStreem(t,
+		&AutomationRule{Name: "likes", Match: AutomationText, Value: "go", Actions: []string{ActionLike}, MaxPerHour: 1})
+	twitterMock := new(mocks.Client)
+	twitterMock.On("Like", mock.Anything, mock.Anything).Return(nil).Once()
+	tw.twitter = twitterMock
+
+	printed := runAutomate(tw,
+		&twitter.Tweet{IDStr: "3", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+		&twitter.Tweet{IDStr: "2", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+		&twitter.Tweet{IDStr: "1", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+	)
+	capped := "automation: rule=\"likes\" action=- tweet=2 user=@gopher result=skipped, the rule has reached its cap of 1 action per hour, " +
+		"matching tweets are skipped until it acts again\n"
+	assert.Equal(t, []string{
+		"automation: rule=\"likes\" action=like tweet=1 user=@gopher result=ok\n",
+		capped,
+	}, printed, "the cap is reported once")
+	assert.Empty(t, runAutomate(tw, &twitter.Tweet{IDStr: "4", Text: "go", User: twitter.User{ScreenName: "gopher"}}))
+	twitterMock.AssertExpectations(t)
+
+	// once the hour is up the rule acts again, and the next cap is reported
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	later := nowSave().Add(time.Hour)
+	timeNow = func() time.Time { return later }
+	twitterMock.On("Like", mock.Anything, mock.Anything).Return(nil).Once()
+	printed = runAutomate(tw,
+		&twitter.Tweet{IDStr: "6", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+		&twitter.Tweet{IDStr: "5", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+	)
+	assert.Equal(t, []string{
+		"automation: rule=\"likes\" action=like tweet=5 user=@gopher result=ok\n",
+		strings.Replace(capped, "tweet=2", "tweet=6", 1),
+	}, printed)
+	twitterMock.AssertExpectations(t)
+}
+
+func TestTweetStreem_PollAndEcho_Automation(t *testing.T) {
+	posted := func(d time.Duration) string {
+		return time.Date(2020, 3, 25, 14, 30, 0, 0, time.UTC).Add(d).Format(twitter.CreatedAtTimeLayout)
+	}
+	old := &twitter.Tweet{IDStr: "1", CreatedAt: posted(-time.Hour), Text: "go", User: twitter.User{ScreenName: "gopher"}}
+	muted := &twitter.Tweet{IDStr: "3", CreatedAt: posted(time.Minute), Text: "go spoilers", User: twitter.User{ScreenName: "gopher"}}
+	shown := &twitter.Tweet{IDStr: "2", CreatedAt: posted(time.Minute), Text: "go", User: twitter.User{ScreenName: "gopher"}}
+	twitterMock := new(mocks.Client)
+	twitterMock.On("StartPoller", mock.Anything).Run(func(args mock.Arguments) {
+		ch := args.Get(0).(chan<- []*twitter.Tweet)
+		go func() {
+			ch <- []*twitter.Tweet{old}
+			ch <- []*twitter.Tweet{muted, shown}
+			close(ch)
+		}()
+	})
+	twitterMock.On("Like", shown, mock.Anything).Return(nil)
+
+	tw := newAutomationTweetStreem(t, &AutomationRule{Name: "likes", Match: AutomationText, Value: "go", Actions: []string{ActionLike}})
+	tw.twitter = twitterMock
+	filter, err := NewFilter(FilterKeyword, "spoilers")
+	assert.NoError(t, err)
+	tw.Filters = []*Filter{filter}
+	tw.EnableArchive = false
+	tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+	assert.NoError(t, tw.parseTemplate())
+
+	assert.Equal(t, []string{
+		"1:gopher\n",
+		"2:gopher\n",
+		"automation: rule=\"likes\" action=like tweet=2 user=@gopher result=ok\n",
+	}, collectPrints(tw, tw.pollAndEcho), "the tweet from before the stream started and the muted tweet are not acted on")
+	twitterMock.AssertExpectations(t)
+}
+
+func TestTweetStreem_ProcessCommand_Automation(t *testing.T) {
+	tw := newAutomationTweetStreem(t,
+		&AutomationRule{Name: "partners", Match: AutomationAuthor, Value: "partner", Actions: []string{ActionLike, ActionRetweet}},
+		&AutomationRule{Name: "thanks", Match: AutomationMention, Actions: []string{ActionReply}, Reply: "thanks!", MaxPerHour: 3},
+	)
+
+	assert.NoError(t, tw.ProcessCommand("auto"))
+	verifyPrint(t, tw, "automations: on, dry run: off\n"+
+		"0: partners (author partner) like, retweet - 0/10 this hour\n"+
+		"1: thanks (mention) reply - 0/3 this hour\n")
+
+	assert.NoError(t, tw.ProcessCommand("auto log"))
+	verifyPrint(t, tw, "no automation log\n")
+
+	assert.NoError(t, tw.ProcessCommand("auto dryrun on"))
+	verifyPrint(t, tw, "automation dry run on\n")
+	assert.True(t, tw.Automation.DryRun)
+	printed := runAutomate(tw, &twitter.Tweet{IDStr: "1", User: twitter.User{ScreenName: "partner"}})
+	assert.Len(t, printed, 2)
+
+	assert.NoError(t, tw.ProcessCommand("auto ls"))
+	verifyPrint(t, tw, "automations: on, dry run: on\n"+
+		"0: partners (author partner) like, retweet - 1/10 this hour\n"+
+		"1: thanks (mention) reply - 0/3 this hour\n")
+
+	assert.NoError(t, tw.ProcessCommand("auto log 1"))
+	verifyPrint(t, tw, "2020-03-25T14:30:00Z rule=\"partners\" action=retweet tweet=1 user=@partner result=dry run\n")
+
+	assert.NoError(t, tw.ProcessCommand("auto off"))
+	verifyPrint(t, tw, "automations off\n")
+	assert.False(t, tw.Automation.Enabled)
+	assert.NoError(t, tw.ProcessCommand("auto on"))
+	verifyPrint(t, tw, "automations on\n")
+	assert.True(t, tw.Automation.Enabled)
+
+	assert.EqualError(t, tw.ProcessCommand("auto dryrun"), "expected 'auto dryrun on' or 'auto dryrun off'")
+	assert.EqualError(t, tw.ProcessCommand("auto log none"), "invalid number of entries: none")
+	assert.EqualError(t, tw.ProcessCommand("auto stop"), `unknown auto command "stop", expected ls, on, off, dryrun or log`)
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// batchWorkers is how many tweets of a batch are acted on at once
+const batchWorkers = 4
+
+// tweetAction acts on the tweet ref, returning the message to print on success.
+type tweetAction func(ref string) (string, error)
+
+// tweetRefs returns the tweets referred to by the first of the arguments that is
+// a number, a tweet url, or a list of numbers and ranges eg: '3,5,7-10'.
+func tweetRefs(args ...string) ([]string, error) {
+	for _, a := range args {
+		if ref, ok := firstTweetRef(a); ok {
+			return []string{ref}, nil
+		}
+		if !isNumberList(a) {
+			continue
+		}
+		nums, err := util.ParseNumberList(a)
+		if err != nil {
+			return nil, err
+		}
+		refs := make([]string, len(nums))
+		for i, n := range nums {
+			refs[i] = strconv.Itoa(n)
+		}
+		return refs, nil
+	}
+	return nil, fmt.Errorf("invalid tweet id")
+}
+
+// isNumberList reports whether s looks like a list of numbers and ranges, rather than some other argument.
+func isNumberList(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789,-") == "" && strings.ContainsAny(s, "0123456789")
+}
+
+type batchResult struct {
+	msg string
+	err error
+}
+
+// commandBatch performs the action on each tweet referred to by the arguments.
+// a single tweet prints the message or returns the error, a batch is acted on by
+// a bounded pool of workers and prints the result of each tweet, in order, then a summary.
+func (t *TweetStreem) commandBatch(action tweetAction, args ...string) error {
+	refs, err := tweetRefs(args...)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 1 {
+		msg, err := action(refs[0])
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			t.print(msg)
+		}
+		return nil
+	}
+
+	results := make([]batchResult, len(refs))
+	jobs := make(chan int)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < batchWorkers && w < len(refs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				msg, err := action(refs[i])
+				results[i] = batchResult{msg, err}
+			}
+		}()
+	}
+	for i := range refs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	out := ""
+	failed := 0
+	for i, res := range results {
+		switch {
+		case res.err != nil:
+			failed++
+			out += fmt.Sprintf("%s: Error: %s\n", refs[i], res.err)
+		case res.msg == "":
+			out += fmt.Sprintf("%s: ok\n", refs[i])
+		default:
+			out += fmt.Sprintf("%s: %s", refs[i], res.msg)
+		}
+	}
+	out += fmt.Sprintf("%d of %d succeeded, %d failed\n", len(refs)-failed, len(refs), failed)
+	t.print(out)
+	return nil
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/twitter/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTweetRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{"single", []string{"3"}, []string{"3"}, ""},
+		{"url", []string{"https://twitter.com/test/status/123"}, []string{"https://twitter.com/test/status/123"}, ""},
+		{"list", []string{"3,5,7-10"}, []string{"3", "5", "7", "8", "9", "10"}, ""},
+		{"after other args", []string{"all", "1-2"}, []string{"1", "2"}, ""},
+		{"invalid range", []string{"5-3"}, nil, `invalid range "5-3"`},
+		{"none", []string{"all"}, nil, "invalid tweet id"},
+		{"empty", nil, nil, "invalid tweet id"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := tweetRefs(test.args...)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Batch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"list", "like 1,3",
+			"1: tweet by @one liked\n3: tweet by @three liked\n2 of 2 succeeded, 0 failed\n"},
+		{"range", "li 1-3",
+			"1: tweet by @one liked\n2: Error: " + assert.AnError.Error() + "\n3: tweet by @three liked\n2 of 3 succeeded, 1 failed\n"},
+		{"unknown", "like 1,9",
+			"1: tweet by @one liked\n9: Error: unknown tweet - id:9\n1 of 2 succeeded, 1 failed\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tweets := []*twitter.Tweet{
+				{IDStr: "101", User: twitter.User{ScreenName: "one"}},
+				{IDStr: "102", User: twitter.User{ScreenName: "two"}},
+				{IDStr: "103", User: twitter.User{ScreenName: "three"}},
+			}
+			twitterMock := new(mocks.Client)
+			twitterMock.On("Like", tweets[0], mock.AnythingOfType("url.Values")).Return(nil)
+			twitterMock.On("Like", tweets[1], mock.AnythingOfType("url.Values")).Return(assert.AnError)
+			twitterMock.On("Like", tweets[2], mock.AnythingOfType("url.Values")).Return(nil)
+
+			tw := NewTweetStreem(context.TODO())
+			tw.twitter = twitterMock
+			for _, tweet := range tweets {
+				tw.tweetHistory.Log(tweet)
+			}
+			assert.NoError(t, tw.ProcessCommand(test.input))
+			verifyPrint(t, tw, test.want)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_BatchInvalid(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	assert.EqualError(t, tw.ProcessCommand("rt 1-500"), `too many numbers in "1-500", at most 100`)
+	assert.EqualError(t, tw.ProcessCommand("bm 1,,-"), `invalid number "-"`)
+	assert.EqualError(t, tw.ProcessCommand("like"), "usage: like <ids>")
+}
+
+func TestTweetStreem_ProcessCommand_OpenAll(t *testing.T) {
+	obSave := openBrowser
+	defer func() { openBrowser = obSave }()
+
+	lock := new(sync.Mutex)
+	var opened []string
+	openBrowser = func(url string) error {
+		lock.Lock()
+		defer lock.Unlock()
+		opened = append(opened, url)
+		return nil
+	}
+
+	tw := NewTweetStreem(context.TODO())
+	tw.tweetHistory.Log(&twitter.Tweet{IDStr: "101", Entities: twitter.Entities{
+		Urls: []twitter.URL{{ExpandedURL: "http://example.com/a"}, {ExpandedURL: "http://example.com/b"}},
+	}})
+	tw.tweetHistory.Log(&twitter.Tweet{IDStr: "102"})
+
+	assert.NoError(t, tw.ProcessCommand("open 1 all"))
+	verifyPrint(t, tw, "opening in browser: http://example.com/a\n")
+	verifyPrint(t, tw, "opening in browser: http://example.com/b\n")
+	assert.Equal(t, []string{"http://example.com/a", "http://example.com/b"}, opened)
+
+	assert.EqualError(t, tw.ProcessCommand("open 2 all"), "tweet has no links")
+}
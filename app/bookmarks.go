@@ -0,0 +1,176 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Setheck/tweetstreem/twitter"
+)
+
+// bookmarks are also kept locally, the v2 bookmark api is not available to every app,
+// and the local copy can be listed and exported offline
+var bookmarksFile = ".tweetstreem_bookmarks.json"
+
+// Bookmarks is a local store of bookmarked tweets, persisted to disk as json.
+type Bookmarks struct {
+	path   string
+	loaded bool
+	tweets []*twitter.Tweet
+	lock   sync.Mutex
+}
+
+// NewBookmarks creates a new instance that persists to the given path.
+func NewBookmarks(path string) *Bookmarks {
+	return &Bookmarks{path: path}
+}
+
+// Add bookmarks the given tweet, returns false if it was already bookmarked.
+func (b *Bookmarks) Add(tw *twitter.Tweet) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err := b.load(); err != nil {
+		return false, err
+	}
+	if b.indexOf(tw.IDStr) >= 0 {
+		return false, nil
+	}
+	b.tweets = append(b.tweets, tw)
+	return true, b.save()
+}
+
+// Remove deletes the bookmark for the given tweet id, returns false if it was not bookmarked.
+func (b *Bookmarks) Remove(idStr string) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err := b.load(); err != nil {
+		return false, err
+	}
+	idx := b.indexOf(idStr)
+	if idx < 0 {
+		return false, nil
+	}
+	b.tweets = append(b.tweets[:idx], b.tweets[idx+1:]...)
+	return true, b.save()
+}
+
+// List returns the bookmarked tweets, in the order they were bookmarked.
+func (b *Bookmarks) List() ([]*twitter.Tweet, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	return append([]*twitter.Tweet(nil), b.tweets...), nil
+}
+
+// Export writes the bookmarked tweets as json to the given writer.
+func (b *Bookmarks) Export(w io.Writer) error {
+	tweets, err := b.List()
+	if err != nil {
+		return err
+	}
+	return writeTweetsJSON(w, tweets)
+}
+
+func writeTweetsJSON(w io.Writer, tweets []*twitter.Tweet) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tweets)
+}
+
+func (b *Bookmarks) indexOf(idStr string) int {
+	for i, tw := range b.tweets {
+		if tw.IDStr == idStr {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Bookmarks) load() error {
+	if b.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		b.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	if err := json.Unmarshal(data, &b.tweets); err != nil {
+		return fmt.Errorf("failed to parse bookmarks %q: %w", b.path, err)
+	}
+	b.loaded = true
+	return nil
+}
+
+func (b *Bookmarks) save() error {
+	data, err := json.Marshal(b.tweets)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(b.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save bookmarks to %q: %w", b.path, err)
+	}
+	return nil
+}
+
+// bookmarkAPI makes the given twitter bookmark api call, returns false if it failed.
+// once a call fails the api is not tried again, and bookmarks are only kept in the local store.
+func (t *TweetStreem) bookmarkAPI(call func() error) bool {
+	if t.noBookmarkAPI.Load() {
+		return false
+	}
+	if err := call(); err != nil {
+		if !t.noBookmarkAPI.Swap(true) {
+			t.println("bookmarks api unavailable, bookmarks are stored locally:", err)
+		}
+		return false
+	}
+	return true
+}
+
+// addBookmark bookmarks the tweet with the api and in the local store,
+// returns false if it was already bookmarked locally.
+func (t *TweetStreem) addBookmark(tw *twitter.Tweet) (bool, error) {
+	t.bookmarkAPI(func() error { return t.twitter.Bookmark(tw) })
+	return t.bookmarks.Add(tw)
+}
+
+// removeBookmark removes the bookmark with the api and from the local store,
+// returns false if it was not bookmarked locally and the api is unavailable.
+func (t *TweetStreem) removeBookmark(tw *twitter.Tweet) (bool, error) {
+	removedAPI := t.bookmarkAPI(func() error { return t.twitter.RemoveBookmark(tw) })
+	removed, err := t.bookmarks.Remove(tw.IDStr)
+	return removed || removedAPI, err
+}
+
+// listBookmarks returns the bookmarked tweets, most recently bookmarked first.
+// the api bookmarks are followed by those only stored locally, like any made while the api was unavailable.
+func (t *TweetStreem) listBookmarks() ([]*twitter.Tweet, error) {
+	var tweets []*twitter.Tweet
+	t.bookmarkAPI(func() (err error) {
+		tweets, err = t.twitter.Bookmarks(twitter.NewURLValues())
+		return err
+	})
+	local, err := t.bookmarks.List()
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string]bool, len(tweets))
+	for _, tw := range tweets {
+		listed[tw.IDStr] = true
+	}
+	for i := len(local) - 1; i >= 0; i-- {
+		if !listed[local[i].IDStr] {
+			tweets = append(tweets, local[i])
+		}
+	}
+	return tweets, nil
+}
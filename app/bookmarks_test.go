@@ -0,0 +1,64 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), bookmarksFile)
+	bookmarks := NewBookmarks(path)
+
+	tweets, err := bookmarks.List()
+	assert.NoError(t, err)
+	assert.Empty(t, tweets)
+
+	tw1 := &twitter.Tweet{IDStr: "1", Text: "one", User: twitter.User{ScreenName: "test"}}
+	tw2 := &twitter.Tweet{IDStr: "2", Text: "two", User: twitter.User{ScreenName: "test"}}
+
+	added, err := bookmarks.Add(tw1)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, err = bookmarks.Add(tw2)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, err = bookmarks.Add(tw1)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	// a fresh store reads the bookmarks back from disk
+	reloaded := NewBookmarks(path)
+	tweets, err = reloaded.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*twitter.Tweet{tw1, tw2}, tweets)
+
+	removed, err := reloaded.Remove("1")
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = reloaded.Remove("1")
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, reloaded.Export(buf))
+	var exported []*twitter.Tweet
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	assert.Equal(t, []*twitter.Tweet{tw2}, exported)
+}
+
+func TestBookmarks_LoadFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), bookmarksFile)
+	assert.NoError(t, os.WriteFile(path, []byte("garbage"), 0600))
+
+	bookmarks := NewBookmarks(path)
+	_, err := bookmarks.List()
+	assert.Error(t, err)
+	_, err = bookmarks.Add(&twitter.Tweet{IDStr: "1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,371 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// Command is an interactive command, the help and README documentation is generated from
+// the registry, see TestCommandsDoc.
+type Command struct {
+	Name    string
+	Aliases []string
+	Args    string // the argument spec, <required> and [optional], eg: '<id> [idx]'
+	Help    string
+	Flags   []Flag
+	Usages  []Usage // the other forms of the command, eg: subcommands
+	Run     func(t *TweetStreem, c *CommandCall) error
+}
+
+// Flag is a command option given as '--name value', '--name=value', or '--name' when it takes no value.
+type Flag struct {
+	Name  string // without the leading '--'
+	Value string // the value shown in help, a flag without one is a switch
+	Help  string
+}
+
+// Usage documents another form of a command.
+type Usage struct {
+	Args string
+	Help string
+}
+
+// commandGroup is a set of commands listed together in help, under the optional header.
+type commandGroup struct {
+	Header   string
+	Commands []*Command
+}
+
+// the command registry, set in init as the help command refers to it
+var (
+	commandGroups []commandGroup
+	commands      map[string]*Command // by name and alias
+)
+
+func init() {
+	commandGroups = []commandGroup{
+		{Commands: []*Command{
+			{Name: "config", Help: "show the current configuration",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.print(t.config()); return nil }},
+			{Name: "pause", Aliases: []string{"p"}, Help: "pause the stream",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.pause(); return nil }},
+			{Name: "resume", Aliases: []string{"r"}, Help: "resume the stream",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.resume(); return nil }},
+			{Name: "version", Aliases: []string{"v", "info"}, Help: "print tweetstreem version",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.print(appInfo()); return nil }},
+		}},
+		{Header: "Select a tweet by id, tweet id or tweet url, eg: 'open 2'\n" +
+			"open, like, unlike, retweet, unretweet, bookmark and unbookmark take a list of ids and ranges, eg: 'like 3,5,7-10'",
+			Commands: []*Command{
+				{Name: "open", Aliases: []string{"o"}, Args: "<ids> [idx|all]",
+					Help: "open the link in the selected tweet (optionally provide 0 based index, or all to open every link)",
+					Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandOpen(c.isRpc, c.Args...) }},
+				{Name: "browse", Aliases: []string{"b"}, Args: "<id>", Help: "open the selected tweet in a browser",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBrowse(c.isRpc, c.Args...) }},
+				{Name: "retweet", Aliases: []string{"rt"}, Args: "<ids>", Help: "retweet the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.reTweet, c.Args...) }},
+				{Name: "unretweet", Aliases: []string{"urt"}, Args: "<ids>", Help: "unretweet the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.unReTweet, c.Args...) }},
+				{Name: "like", Aliases: []string{"li"}, Args: "<ids>", Help: "like the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.like, c.Args...) }},
+				{Name: "unlike", Aliases: []string{"ul"}, Args: "<ids>", Help: "unlike the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.unLike, c.Args...) }},
+				{Name: "reply", Args: "<id> <status>", Help: "reply to the tweet id (requires user mention, and confirmation)",
+					Run: func(t *TweetStreem, c *CommandCall) error { t.commandReply(c.Args[0], c.Text(1)); return nil }},
+				{Name: "cbreply", Args: "<id>", Help: "reply to tweet id with clipboard contents (requires confirmation)",
+					Run: func(t *TweetStreem, c *CommandCall) error { t.clipBoardReply(c.Args...); return nil }},
+				{Name: "bookmark", Aliases: []string{"bm"}, Args: "<ids>", Help: "bookmark the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.bookmark, c.Args...) }},
+				{Name: "unbookmark", Aliases: []string{"ubm"}, Args: "<ids>", Help: "remove the bookmark for the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandBatch(t.unBookmark, c.Args...) }},
+				{Name: "retweeters", Args: "<id>", Help: "list the users who retweeted the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error {
+						return t.commandListUsers(t.twitter.Retweeters, c.Args...)
+					}},
+				{Name: "likers", Args: "<id>", Help: "list the users who liked the selected tweet",
+					Run: func(t *TweetStreem, c *CommandCall) error { return t.commandListUsers(t.twitter.Likers, c.Args...) }},
+				{Name: "preview", Args: "<id> [idx]",
+					Help: "show the media in the selected tweet as images (optionally provide 0 based index)",
+					Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandPreview(c.Args...) }},
+			}},
+		{Commands: []*Command{
+			{Name: "follow", Args: "<@screen_name|index>",
+				Help: "follow a user, by name or 0 based index from the last user list",
+				Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandFollow(c.Args...) }},
+			{Name: "whois", Args: "<@screen_name|index>",
+				Help: "show a user's profile, by name or 0 based index from the last user list",
+				Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandWhois(c.Args...) }},
+			{Name: "tweet", Aliases: []string{"t"}, Args: "<status>",
+				Help: "create a new tweet and post (requires confirmation), the status is posted as typed, quotes included",
+				Flags: []Flag{
+					{Name: "poll", Value: `"A|B|C"`, Help: "attach a poll with 2 to 4 | separated options"},
+					{Name: "duration", Value: "1d", Help: "how long the poll is open, 5m to 7d (default 1d)"},
+				},
+				Run: func(t *TweetStreem, c *CommandCall) error { t.commandTweet(c); return nil }},
+			{Name: "me", Help: "view your recent tweets",
+				Run: func(t *TweetStreem, c *CommandCall) error {
+					return t.userTimeline(t.twitter.ScreenName(), twitter.NewURLValues())
+				}},
+			{Name: "user", Aliases: []string{"u"}, Args: "<@screen_name|id[.mention]> [count]",
+				Help: "view a user's recent tweets, an id selects the author of that tweet, " +
+					"id.N selects the Nth 0 based user mentioned in it",
+				Flags: []Flag{
+					{Name: "no-replies", Help: "leave out replies"},
+					{Name: "no-rts", Help: "leave out retweets"},
+				},
+				Run: func(t *TweetStreem, c *CommandCall) error {
+					return t.commandUser(c.Bool("no-replies"), c.Bool("no-rts"), c.Args...)
+				}},
+			{Name: "home", Help: "view your default timeline",
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.homeTimeline() }},
+			{Name: "likes", Args: "[@screen_name|id]", Help: "view tweets liked by you, or the given user",
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.commandLikes(c.Args...) }},
+			{Name: "bookmarks", Args: "[export <file>]",
+				Help: "view your bookmarked tweets, or export them as json to a file",
+				Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandBookmarks(c.Args...) }},
+			{Name: "find", Args: "[terms] [from:<screen_name>] [has:link] [since:<2006-01-02|2d>]",
+				Help: "search the tweets you have seen, quote a phrase to search for it as one term",
+				Run:  func(t *TweetStreem, c *CommandCall) error { return t.commandFind(c.Args...) }},
+			{Name: "filter", Args: "[ls]", Help: "list the mute filters, and the number of tweets each has hidden",
+				Usages: []Usage{
+					{"add <keyword|regex|user|app|lang> <value>", "mute tweets matching the value"},
+					{"add <retweets|nolinks>", "mute all retweets, or tweets without links"},
+					{"rm <index>", "remove the filter by 0 based index"},
+					{"test <id>", "list the filters that mute the selected tweet"},
+				},
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.commandFilter(c.Args...) }},
+			{Name: "automation", Aliases: []string{"auto"}, Args: "[ls]",
+				Help: "show the automations, and how many times each acted in the last hour",
+				Usages: []Usage{
+					{"on|off", "turn all automations on or off"},
+					{"dryrun on|off", "audit the actions automations would perform, without performing them"},
+					{"log [count]", "show the most recent entries of the automation audit log (default 10)"},
+				},
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.commandAutomation(c.Args...) }},
+			{Name: "theme", Args: "[name]", Help: "list the available themes, or switch to the named theme",
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.commandTheme(c.Args...) }},
+			{Name: "more", Aliases: []string{"older"}, Help: "view the next page of older tweets for the current timeline (likes are paged by tweet id, so likes of older tweets can be skipped)",
+				Run: func(t *TweetStreem, c *CommandCall) error { return t.olderTimeline() }},
+			{Name: "help", Aliases: []string{"h"}, Help: "this help menu",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.print(t.help()); return nil }},
+			{Name: "quit", Aliases: []string{"q", "exit"}, Help: "exit tweetstreem",
+				Run: func(t *TweetStreem, c *CommandCall) error { t.cancel(); return nil }},
+		}},
+	}
+
+	commands = make(map[string]*Command)
+	for _, g := range commandGroups {
+		for _, c := range g.Commands {
+			for _, name := range c.names() {
+				if _, ok := commands[name]; ok {
+					panic(fmt.Sprintf("duplicate command name %q", name))
+				}
+				commands[name] = c
+			}
+		}
+	}
+}
+
+// names returns the name and aliases of the command, shortest first.
+func (c *Command) names() []string {
+	names := append([]string{c.Name}, c.Aliases...)
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) < len(names[j]) })
+	return names
+}
+
+// usage returns the flags and arguments of the command, eg: '[--poll "A|B|C"] <status>'
+func (c *Command) usage() string {
+	var parts []string
+	for _, f := range c.Flags {
+		if f.Value == "" {
+			parts = append(parts, fmt.Sprintf("[--%s]", f.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[--%s %s]", f.Name, f.Value))
+		}
+	}
+	if c.Args != "" {
+		parts = append(parts, c.Args)
+	}
+	return strings.Join(parts, " ")
+}
+
+// requiredArgs counts the <required> arguments of the spec, those not inside [optional] ones.
+func (c *Command) requiredArgs() int {
+	count, depth, inArg := 0, 0, false
+	for _, r := range c.Args {
+		switch {
+		case inArg:
+			inArg = r != '>'
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '<':
+			inArg = true
+			if depth == 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (c *Command) flag(name string) (Flag, bool) {
+	for _, f := range c.Flags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// CommandCall is a command line parsed for its command.
+type CommandCall struct {
+	Command *Command
+	Name    string            // the command name or alias as typed
+	Args    []string          // the arguments, without the flags
+	Flags   map[string]string // the flags given, a switch has no value
+
+	isRpc  bool
+	line   string
+	words  []util.Word // of the command line
+	argIdx []int       // the index in words of each argument
+}
+
+// errUnknownCommand is returned for a command line that does not start with a command.
+var errUnknownCommand = errors.New("unknown command")
+
+// parseCommandLine splits the line into words, finds the command and separates its flags and arguments,
+// flags are only parsed for commands that have them, and a '--' ends the flags. free text like a tweet
+// also ends the flags, so the text is kept as typed.
+func parseCommandLine(line string) (*CommandCall, error) {
+	words := util.SplitWords(line)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	name := strings.ToLower(words[0].Text)
+	cmd, ok := commands[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownCommand, name)
+	}
+	c := &CommandCall{Name: name, Command: cmd, Flags: make(map[string]string), line: line, words: words}
+	flags := len(cmd.Flags) > 0
+	freeText := strings.HasSuffix(cmd.Args, "<status>")
+	for i := 1; i < len(words); i++ {
+		w := words[i].Text
+		if flags && w == "--" {
+			flags = false
+			continue
+		}
+		if !flags || !strings.HasPrefix(w, "--") {
+			c.Args = append(c.Args, w)
+			c.argIdx = append(c.argIdx, i)
+			flags = flags && !freeText
+			continue
+		}
+		flagName, value, hasValue := strings.Cut(w[2:], "=")
+		flagName = strings.ToLower(flagName)
+		f, ok := cmd.flag(flagName)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("unknown flag %s for %s, see 'help'", w, cmd.Name)
+		case f.Value == "" && hasValue:
+			return nil, fmt.Errorf("--%s takes no value", f.Name)
+		case f.Value != "" && !hasValue:
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("--%s requires a value", f.Name)
+			}
+			i++
+			value = words[i].Text
+		}
+		c.Flags[f.Name] = value
+	}
+	if len(c.Args) < cmd.requiredArgs() {
+		return nil, fmt.Errorf("usage: %s %s", cmd.Name, cmd.usage())
+	}
+	return c, nil
+}
+
+// Flag returns the value of the flag, and whether it was given.
+func (c *CommandCall) Flag(name string) (string, bool) {
+	v, ok := c.Flags[name]
+	return v, ok
+}
+
+// Bool reports whether the switch was given.
+func (c *CommandCall) Bool(name string) bool {
+	_, ok := c.Flags[name]
+	return ok
+}
+
+// Text returns the command line from the i-th argument on as it was typed, spacing and quotes included,
+// for free text like a tweet. any flags in between are left out.
+func (c *CommandCall) Text(i int) string {
+	var sb strings.Builder
+	for j := i; j < len(c.argIdx); j++ {
+		w := c.words[c.argIdx[j]]
+		if j > i {
+			if c.argIdx[j] == c.argIdx[j-1]+1 {
+				sb.WriteString(c.line[c.words[c.argIdx[j-1]].End:w.Start])
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(c.line[w.Start:w.End])
+	}
+	return sb.String()
+}
+
+// commandsHelp is the help text of the registered commands.
+func commandsHelp() string {
+	var sb strings.Builder
+	for _, g := range commandGroups {
+		indent := ""
+		if g.Header != "" {
+			sb.WriteString(g.Header + "\n")
+			indent = " "
+		}
+		for _, c := range g.Commands {
+			sb.WriteString(fmt.Sprintf("%s%s - %s\n", indent, joinNonEmpty(strings.Join(c.names(), ","), c.usage()), c.Help))
+			for _, f := range c.Flags {
+				sb.WriteString(fmt.Sprintf("%s  %s - %s\n", indent, joinNonEmpty("--"+f.Name, f.Value), f.Help))
+			}
+			for _, u := range c.Usages {
+				sb.WriteString(fmt.Sprintf("%s %s %s - %s\n", indent, c.Name, u.Args, u.Help))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// commandsDoc is the README documentation of the registered commands.
+func commandsDoc() string {
+	var sb strings.Builder
+	for _, g := range commandGroups {
+		if g.Header != "" {
+			sb.WriteString("\n" + g.Header + "\n")
+		}
+		for _, c := range g.Commands {
+			sb.WriteString(fmt.Sprintf("* `%s` - %s\n", joinNonEmpty(strings.Join(c.names(), ","), c.usage()), c.Help))
+			for _, f := range c.Flags {
+				sb.WriteString(fmt.Sprintf("  * `%s` - %s\n", joinNonEmpty("--"+f.Name, f.Value), f.Help))
+			}
+			for _, u := range c.Usages {
+				sb.WriteString(fmt.Sprintf("  * `%s %s` - %s\n", c.Name, u.Args, u.Help))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func joinNonEmpty(a, b string) string {
+	if b == "" {
+		return a
+	}
+	return a + " " + b
+}
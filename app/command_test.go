@@ -0,0 +1,164 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	commandsDocStart = "<!-- commands -->\n"
+	commandsDocEnd   = "<!-- end commands -->"
+)
+
+// TestCommandsDoc verifies the README documents every command, run `make docs` to regenerate it.
+func TestCommandsDoc(t *testing.T) {
+	readme := filepath.Join("..", "README.md")
+	data, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	before, rest, found := strings.Cut(content, commandsDocStart)
+	if !assert.True(t, found, "README is missing the commands marker") {
+		return
+	}
+	_, after, found := strings.Cut(rest, commandsDocEnd)
+	if !assert.True(t, found, "README is missing the end commands marker") {
+		return
+	}
+	want := before + commandsDocStart + commandsDoc() + commandsDocEnd + after
+	if *updateDocs {
+		if err := os.WriteFile(readme, []byte(want), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	assert.Equal(t, want, content, "README commands are out of date, run: make docs")
+}
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		command string
+		args    []string
+		flags   map[string]string
+	}{
+		{"command", "help", "help", nil, map[string]string{}},
+		{"alias", "H", "help", nil, map[string]string{}},
+		{"args keep case", "THEME Dark", "theme", []string{"Dark"}, map[string]string{}},
+		{"extra spaces", "  like   1  ", "like", []string{"1"}, map[string]string{}},
+		{"quoted", `find "two words" go`, "find", []string{"two words", "go"}, map[string]string{}},
+		{"no flags", "find --poll", "find", []string{"--poll"}, map[string]string{}},
+		{"flags", "user @me 10 --NO-RTS", "user", []string{"@me", "10"}, map[string]string{"no-rts": ""}},
+		{"flag value", `tweet --poll "A|B" hi`, "tweet", []string{"hi"}, map[string]string{"poll": "A|B"}},
+		{"flag equals", `tweet --duration=2d --poll=A|B hi`, "tweet", []string{"hi"},
+			map[string]string{"poll": "A|B", "duration": "2d"}},
+		{"end of flags", `tweet -- --poll`, "tweet", []string{"--poll"}, map[string]string{}},
+		{"free text ends flags", `tweet use --help to see`, "tweet", []string{"use", "--help", "to", "see"}, map[string]string{}},
+		{"dash dash in free text", `tweet wait -- what`, "tweet", []string{"wait", "--", "what"}, map[string]string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			call, err := parseCommandLine(test.input)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.command, call.Command.Name)
+			assert.Equal(t, test.args, call.Args)
+			assert.Equal(t, test.flags, call.Flags)
+		})
+	}
+}
+
+func TestParseCommandLine_Empty(t *testing.T) {
+	call, err := parseCommandLine("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, call)
+}
+
+func TestParseCommandLine_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"unknown command", "Nope 1", "unknown command: nope"},
+		{"unknown flag", "user @me --no-likes", "unknown flag --no-likes for user, see 'help'"},
+		{"missing value", "tweet --poll", "--poll requires a value"},
+		{"switch value", "user @me --no-rts=yes", "--no-rts takes no value"},
+		{"required args", "reply 1", "usage: reply <id> <status>"},
+		{"required args with flags", "tweet --poll A|B", `usage: tweet [--poll "A|B|C"] [--duration 1d] <status>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseCommandLine(test.input)
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestCommandCall_Text(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		idx   int
+		want  string
+	}{
+		{"as typed", `reply 1 it's  "quoted" \o/`, 1, `it's  "quoted" \o/`},
+		{"flags left out", `user @me --no-rts  10`, 0, "@me 10"},
+		{"text after flags", `tweet --poll A|B one --two  three`, 0, "one --two  three"},
+		{"past the end", "reply 1 hi", 2, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			call, err := parseCommandLine(test.input)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.want, call.Text(test.idx))
+		})
+	}
+}
+
+func TestCommand_RequiredArgs(t *testing.T) {
+	tests := []struct {
+		args string
+		want int
+	}{
+		{"", 0},
+		{"[name]", 0},
+		{"<id> [idx]", 1},
+		{"<id> <status>", 2},
+		{"[export <file>]", 0},
+		{"<@screen_name|id[.mention]> [count]", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			assert.Equal(t, test.want, (&Command{Args: test.args}).requiredArgs())
+		})
+	}
+}
+
+func TestCommand_Names(t *testing.T) {
+	assert.Equal(t, []string{"q", "quit", "exit"}, commands["exit"].names())
+	assert.Equal(t, []string{"o", "open"}, commands["open"].names())
+}
+
+func TestCommandsHelp(t *testing.T) {
+	help := commandsHelp()
+	for _, g := range commandGroups {
+		for _, c := range g.Commands {
+			assert.Contains(t, help, strings.Join(c.names(), ",")+" ")
+			assert.NotNil(t, c.Run, c.Name)
+		}
+	}
+	assert.Contains(t, help, " o,open <ids> [idx|all] - open the link")
+	assert.Contains(t, help, "t,tweet [--poll \"A|B|C\"] [--duration 1d] <status> - create a new tweet")
+	assert.Contains(t, help, "  --no-rts - leave out retweets\n")
+	assert.Contains(t, help, " filter rm <index> - remove the filter")
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/Setheck/tweetstreem/twitter"
 	"github.com/Setheck/tweetstreem/util"
 	"github.com/spf13/viper"
 )
@@ -40,18 +43,82 @@ func init() {
 	tsViper.AddConfigPath(configPath)
 }
 
-// LoadConfig will attempt to load the tweetstreem configuration file.
+// LoadConfig will attempt to load the tweetstreem configuration file. A section of the configuration
+// that is not valid falls back to its defaults so the rest still loads, and the errors are returned.
 func (t *TweetStreem) LoadConfig() error {
+	var errs configErrors
 	if err := tsViper.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		errs = append(errs, fmt.Errorf("failed to read config file: %w", err))
+	} else if err := tsViper.UnmarshalKey("config", t); err != nil {
+		errs = append(errs, fmt.Errorf("unmarshalling config failed: %w", err))
 	}
-	if err := tsViper.UnmarshalKey("config", t); err != nil {
-		return fmt.Errorf("unmarshalling config failed: %w", err)
+	errs = append(errs, t.applyConfig()...)
+	if len(errs) > 0 {
+		return errs
 	}
+	return nil
+}
+
+// applyConfig validates each section of the configuration, resetting the invalid ones to their defaults,
+// and parses the tweet template, so tweets can always be printed.
+func (t *TweetStreem) applyConfig() configErrors {
+	var errs configErrors
+	invalid := func(section string, err error) {
+		errs = append(errs, fmt.Errorf("invalid %s, using the default: %w", section, err))
+	}
+	if t.Theme.Name != "" && len(t.Theme.Colors) == 0 {
+		// only the name was configured, so look up the palette
+		if theme, err := findTheme(t.Theme.Name); err != nil {
+			invalid("theme", err)
+			t.Theme = DefaultTheme()
+		} else {
+			_ = t.setTheme(theme) // the template is parsed below
+		}
+	}
+	if _, err := util.ParseImageProtocol(t.Preview.Protocol); err != nil {
+		invalid("preview protocol", err)
+		t.Preview.Protocol = string(util.ImageProtocolAuto)
+	}
+	if err := t.validateHighlightRules(); err != nil {
+		invalid("highlightRules", err)
+		t.HighlightRules = nil
+	}
+	if err := t.validateAutomations(); err != nil {
+		invalid("automation", err)
+		t.Automation = DefaultAutomationConfig()
+	}
+	if err := t.compileFilters(); err != nil {
+		invalid("filters", err)
+		t.Filters = nil
+	}
+	if t.HistorySize > 0 {
+		t.tweetHistory = NewHistory(t.HistorySize)
+	}
+	loc, err := twitter.LoadLocation(t.TemplateOutputConfig.TimeZone)
+	if err != nil {
+		invalid("timeZone", err)
+		loc = time.Local
+	}
+	t.TemplateOutputConfig.Location = loc
 	if err := t.parseTemplate(); err != nil {
-		return err
+		invalid("tweetTemplate", err)
+		t.TweetTemplate = DefaultTweetTemplate
+		if err := t.parseTemplate(); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	return errs
+}
+
+// configErrors are the errors found loading the configuration, one per line.
+type configErrors []error
+
+func (e configErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
 }
 
 // SaveConfig writes the current tweetstreem configuration to the configuration file.
@@ -1,11 +1,16 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Setheck/tweetstreem/app/mocks"
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -64,7 +69,43 @@ func TestLoadConfig_UnmarshalKeyFailure(t *testing.T) {
 	viperMock.AssertExpectations(t)
 }
 
+func TestLoadConfig_TimeZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeZone string
+		want     *time.Location
+		wantErr  bool
+	}{
+		{"local", "", time.Local, false},
+		{"utc", "UTC", time.UTC, false},
+		{"invalid", "Nowhere/Special", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viperMock := new(mocks.Viper)
+			viperMock.On("ReadInConfig").Return(nil)
+			viperMock.On("UnmarshalKey", "config", mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(*TweetStreem).TemplateOutputConfig.TimeZone = test.timeZone
+				}).
+				Return(nil)
+			tsViper = viperMock
+
+			ts := NewTweetStreem(context.TODO())
+			err := ts.LoadConfig()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, ts.TemplateOutputConfig.Location)
+		})
+	}
+}
+
 func TestSaveConfig_Success(t *testing.T) {
+	pathSave, fileSave := configPath, configFile
+	t.Cleanup(func() { configPath, configFile = pathSave, fileSave })
 	configPath, configFile = "testConfigPath", "testConfigFile"
 	wantPath := fmt.Sprint(filepath.Join(configPath, configFile), ".", configFormat)
 
@@ -86,6 +127,8 @@ func TestSaveConfig_Success(t *testing.T) {
 }
 
 func TestSaveConfig_WriteConfigAsFailure(t *testing.T) {
+	pathSave, fileSave := configPath, configFile
+	t.Cleanup(func() { configPath, configFile = pathSave, fileSave })
 	configPath, configFile = "testConfigPath", "testConfigFile"
 	wantPath := fmt.Sprint(filepath.Join(configPath, configFile), ".", configFormat)
 
@@ -105,3 +148,159 @@ func TestSaveConfig_WriteConfigAsFailure(t *testing.T) {
 	assert.Error(t, err)
 	viperMock.AssertExpectations(t)
 }
+
+func TestLoadConfig_Filters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []*Filter
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", []*Filter{{Kind: "keyword", Value: "spoilers"}, {Kind: "Regex", Value: "^RT"}}, false},
+		{"invalid kind", []*Filter{{Kind: "colour", Value: "red"}}, true},
+		{"invalid regex", []*Filter{{Kind: "regex", Value: "("}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viperMock := new(mocks.Viper)
+			viperMock.On("ReadInConfig").Return(nil)
+			viperMock.On("UnmarshalKey", "config", mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(*TweetStreem).Filters = test.filters
+				}).
+				Return(nil)
+			tsViper = viperMock
+
+			ts := NewTweetStreem(context.TODO())
+			err := ts.LoadConfig()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, f := range ts.Filters {
+				assert.Equal(t, strings.ToLower(f.Kind), f.Kind)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_HighlightRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []*HighlightRule
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", []*HighlightRule{{Match: "Mention", Bell: true}, {Match: "hashtag", Value: "golang", Color: "bold red"}}, false},
+		{"invalid match", []*HighlightRule{{Match: "user", Value: "me"}}, true},
+		{"invalid color", []*HighlightRule{{Match: "mention", Color: "shiny"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viperMock := new(mocks.Viper)
+			viperMock.On("ReadInConfig").Return(nil)
+			viperMock.On("UnmarshalKey", "config", mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(*TweetStreem).HighlightRules = test.rules
+				}).
+				Return(nil)
+			tsViper = viperMock
+
+			ts := NewTweetStreem(context.TODO())
+			err := ts.LoadConfig()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, r := range ts.HighlightRules {
+				assert.Equal(t, strings.ToLower(r.Match), r.Match)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Automations(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []*AutomationRule
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", []*AutomationRule{{Name: "partners", Match: "author", Value: "partner", Actions: []string{"LIKE", "retweet"}}}, false},
+		{"invalid action", []*AutomationRule{{Name: "partners", Match: "author", Value: "partner", Actions: []string{"follow"}}}, true},
+		{"invalid reply", []*AutomationRule{{Name: "thanks", Match: "mention", Actions: []string{"reply"}, Reply: "{{"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viperMock := new(mocks.Viper)
+			viperMock.On("ReadInConfig").Return(nil)
+			viperMock.On("UnmarshalKey", "config", mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(*TweetStreem).Automation.Rules = test.rules
+				}).
+				Return(nil)
+			tsViper = viperMock
+
+			ts := NewTweetStreem(context.TODO())
+			err := ts.LoadConfig()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, r := range ts.Automation.Rules {
+				for _, action := range r.Actions {
+					assert.Equal(t, strings.ToLower(action), action)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_InvalidSections(t *testing.T) {
+	withThemesDir(t, nil)
+	viperSave := tsViper
+	defer func() { tsViper = viperSave }()
+
+	viperMock := new(mocks.Viper)
+	viperMock.On("ReadInConfig").Return(nil)
+	viperMock.On("UnmarshalKey", "config", mock.Anything).
+		Run(func(args mock.Arguments) {
+			ts := args.Get(1).(*TweetStreem)
+			ts.Theme = Theme{Name: "gone"}
+			ts.Preview.Protocol = "smoke"
+			ts.HighlightRules = []*HighlightRule{{Match: "user", Value: "me"}}
+			ts.Automation.Rules = []*AutomationRule{{Name: "partners", Match: "author", Actions: []string{"follow"}}}
+			ts.Filters = []*Filter{{Kind: "regex", Value: "("}}
+			ts.TemplateOutputConfig.TimeZone = "Nowhere/Special"
+			ts.TweetTemplate = "{{ .Missing"
+		}).
+		Return(nil)
+	tsViper = viperMock
+
+	ts := NewTweetStreem(context.TODO())
+	err := ts.LoadConfig()
+	if !assert.Error(t, err) {
+		return
+	}
+	for _, section := range []string{"theme", "preview protocol", "highlightRules", "automation", "filters", "timeZone", "tweetTemplate"} {
+		assert.Contains(t, err.Error(), "invalid "+section+", using the default")
+	}
+	assert.Equal(t, DefaultTheme(), ts.Theme)
+	assert.Equal(t, string(util.ImageProtocolAuto), ts.Preview.Protocol)
+	assert.Empty(t, ts.HighlightRules)
+	assert.Equal(t, DefaultAutomationConfig(), ts.Automation, "automations fall back to dry run")
+	assert.Empty(t, ts.Filters)
+	assert.Equal(t, time.Local, ts.TemplateOutputConfig.Location)
+	assert.Equal(t, DefaultTweetTemplate, ts.TweetTemplate)
+
+	ts.PrintTweets([]*twitter.Tweet{{IDStr: "1", User: twitter.User{Name: "name", ScreenName: "name"}}})
+	select {
+	case printed := <-ts.printCh:
+		assert.Contains(t, printed, "name")
+	case <-time.After(time.Second):
+		t.Error("the tweet was not printed")
+	}
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// the commands entered in the line editor are kept in the config path
+var lineHistoryFile = ".tweetstreem_history"
+
+// maxCompletions is the most candidates a completion lists, the most recent first
+const maxCompletions = 50
+
+// test point
+var rawInput = util.RawInput
+
+// startLineEditor reads commands with the line editor when stdin is a terminal that supports it,
+// otherwise commands are read a line at a time.
+func (t *TweetStreem) startLineEditor() {
+	f, ok := stdin.(*os.File)
+	if !t.EnableLineEditor || !ok || !util.IsTerminal(f) {
+		return
+	}
+	restore, err := rawInput(f)
+	if err != nil {
+		return
+	}
+	t.lineHistory = util.NewLineHistory(filepath.Join(configPath, lineHistoryFile), util.DefaultLineHistorySize)
+	if err := t.lineHistory.Load(); err != nil {
+		fmt.Println("Error: failed to read command history:", err)
+	}
+	t.editor = util.NewLineEditor(f, os.Stdout, t.lineHistory)
+	t.editor.Complete = t.complete
+	t.restoreInput = restore
+}
+
+// stopLineEditor returns the terminal to reading a line at a time.
+func (t *TweetStreem) stopLineEditor() {
+	if t.restoreInput != nil {
+		_ = t.restoreInput()
+		t.restoreInput = nil
+	}
+}
+
+// readLines sends the lines read by the line editor as input, ctrl-d quits.
+func (t *TweetStreem) readLines() {
+	for {
+		line, err := t.editor.ReadLine()
+		if err != nil {
+			t.cancel()
+			return
+		}
+		select {
+		case t.inputCh <- line:
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// addLineHistory keeps the command for the line editor to recall.
+func (t *TweetStreem) addLineHistory(input string) {
+	if t.lineHistory == nil {
+		return
+	}
+	if err := t.lineHistory.Add(input); err != nil {
+		t.println("Error: failed to save command history:", err)
+	}
+}
+
+// complete returns the candidates for the word being typed, and where it starts in the line:
+// the command names for the first word, otherwise screen names for '@', hashtags for '#', and
+// history ids for arguments that are a tweet id.
+func (t *TweetStreem) complete(line string) (int, []string) {
+	words := util.SplitWords(line)
+	start, word := len(line), ""
+	if n := len(words); n > 0 && words[n-1].End == len(line) {
+		start, word = words[n-1].Start, line[words[n-1].Start:]
+		words = words[:n-1]
+	}
+	switch {
+	case len(words) == 0:
+		return start, completeCommands(word)
+	case strings.HasPrefix(word, "@"):
+		return start, t.completeScreenNames(word)
+	case strings.HasPrefix(word, "#"):
+		return start, t.completeHashtags(word)
+	}
+	cmd, ok := commands[strings.ToLower(words[0].Text)]
+	if !ok {
+		return start, nil
+	}
+	switch cmd.argKind(len(words) - 1) {
+	case "id":
+		return start, t.completeIds(word)
+	case "@screen_name":
+		return start, t.completeScreenNames("@" + word)
+	}
+	return start, nil
+}
+
+// argKind returns 'id' when the argument at the index can be a tweet id, or '@screen_name' when it can be a user.
+func (c *Command) argKind(idx int) string {
+	var args []string
+	depth, arg := 0, ""
+	for _, r := range c.Args {
+		switch r {
+		case '[', '<':
+			depth++
+		case ']', '>':
+			depth--
+		case ' ':
+			if depth == 0 {
+				args, arg = append(args, arg), ""
+				continue
+			}
+		}
+		arg += string(r)
+	}
+	args = append(args, arg)
+	if last := len(args) - 1; idx > last && args[last] == "<ids>" {
+		idx = last // the ids can be given as separate arguments
+	}
+	if idx >= len(args) {
+		return ""
+	}
+	kind := ""
+	for _, alt := range strings.Split(strings.Trim(args[idx], "<>[]"), "|") {
+		switch {
+		case alt == "id", alt == "ids", strings.HasPrefix(alt, "id["):
+			return "id"
+		case alt == "@screen_name":
+			kind = alt
+		}
+	}
+	return kind
+}
+
+// completeCommands returns the command names and aliases that start with the prefix.
+func completeCommands(prefix string) []string {
+	var names []string
+	for name := range commands {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeIds returns the history ids that start with the prefix, the most recent first.
+func (t *TweetStreem) completeIds(prefix string) []string {
+	var ids []string
+	t.tweetHistory.Recent(func(idx int, tw *twitter.Tweet) bool {
+		if id := strconv.Itoa(idx); strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+		return len(ids) < maxCompletions
+	})
+	return ids
+}
+
+// completeScreenNames returns the '@' prefixed screen names of the authors, retweeters and users
+// mentioned in the history that start with the prefix, ignoring case, the most recent first.
+func (t *TweetStreem) completeScreenNames(prefix string) []string {
+	return t.completeHistory(prefix, func(tw *twitter.Tweet) []string {
+		names := []string{"@" + tw.User.ScreenName}
+		orig := tw.Original()
+		if orig != tw {
+			names = append(names, "@"+orig.User.ScreenName)
+		}
+		for _, um := range orig.Entities.UserMention {
+			names = append(names, "@"+um.ScreenName)
+		}
+		return names
+	})
+}
+
+// completeHashtags returns the '#' prefixed hashtags in the history that start with the prefix, ignoring case,
+// the most recent first.
+func (t *TweetStreem) completeHashtags(prefix string) []string {
+	return t.completeHistory(prefix, func(tw *twitter.Tweet) []string {
+		var tags []string
+		for _, ht := range tw.Original().Entities.HashTags {
+			tags = append(tags, "#"+ht.Text)
+		}
+		return tags
+	})
+}
+
+// completeHistory returns the words of the tweets in the history that start with the prefix,
+// without duplicates, ignoring case.
+func (t *TweetStreem) completeHistory(prefix string, tweetWords func(tw *twitter.Tweet) []string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	prefix = strings.ToLower(prefix)
+	t.tweetHistory.Recent(func(idx int, tw *twitter.Tweet) bool {
+		for _, w := range tweetWords(tw) {
+			lower := strings.ToLower(w)
+			if len(w) > 1 && !seen[lower] && strings.HasPrefix(lower, prefix) {
+				seen[lower] = true
+				found = append(found, w)
+			}
+		}
+		return len(found) < maxCompletions
+	})
+	return found
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTweetStreem_Complete(t *testing.T) {
+	ts := NewTweetStreem(context.TODO())
+	ts.tweetHistory.Log(&twitter.Tweet{
+		User: twitter.User{ScreenName: "Alice"},
+		Entities: twitter.Entities{
+			HashTags:    []twitter.HashTag{{Text: "golang"}},
+			UserMention: []twitter.UserMention{{ScreenName: "bob"}},
+		},
+	})
+	ts.tweetHistory.Log(&twitter.Tweet{
+		User: twitter.User{ScreenName: "carol"},
+		ReTweetedStatus: &twitter.Tweet{
+			User:     twitter.User{ScreenName: "alice"},
+			Entities: twitter.Entities{HashTags: []twitter.HashTag{{Text: "gophers"}, {Text: "go"}}},
+		},
+	})
+	for i := 0; i < 10; i++ {
+		ts.tweetHistory.Log(&twitter.Tweet{User: twitter.User{ScreenName: "carol"}})
+	}
+
+	tests := []struct {
+		name  string
+		line  string
+		start int
+		want  []string
+	}{
+		{"command", "unl", 0, []string{"unlike"}},
+		{"commands", "li", 0, []string{"li", "like", "likers", "likes"}},
+		{"command ignores case", "HOM", 0, []string{"home"}},
+		{"unknown command", "zz", 0, nil},
+		{"screen name", "tweet hi @a", 9, []string{"@alice"}},
+		{"screen names", "tweet hi @", 9, []string{"@carol", "@alice", "@bob"}},
+		{"retweeter and mention", "tweet @c", 6, []string{"@carol"}},
+		{"screen name argument", "whois b", 6, []string{"@bob"}},
+		{"hashtags", "tweet #go", 6, []string{"#gophers", "#go", "#golang"}},
+		{"id", "like 1", 5, []string{"12", "11", "10", "1"}},
+		{"ids", "like 2 1", 7, []string{"12", "11", "10", "1"}},
+		{"id of reply", "reply 5", 6, []string{"5"}},
+		{"no id for the status", "reply 5 1", 8, nil},
+		{"after a space", "preview ", 8, []string{"12", "11", "10", "9", "8", "7", "6", "5", "4", "3", "2", "1"}},
+		{"not an id", "theme 1", 6, nil},
+		{"unknown command args", "zz 1", 3, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, got := ts.complete(test.line)
+			assert.Equal(t, test.start, start)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCommand_ArgKind(t *testing.T) {
+	tests := []struct {
+		args string
+		idx  int
+		want string
+	}{
+		{"<id>", 0, "id"},
+		{"<id>", 1, ""},
+		{"<ids> [idx|all]", 1, ""},
+		{"<ids>", 3, "id"},
+		{"<@screen_name|id[.mention]> [count]", 0, "id"},
+		{"<@screen_name|index>", 0, "@screen_name"},
+		{"[export <file>]", 0, ""},
+		{"", 0, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			c := &Command{Args: test.args}
+			assert.Equal(t, test.want, c.argKind(test.idx))
+		})
+	}
+}
+
+func TestTweetStreem_StartLineEditor(t *testing.T) {
+	ts := NewTweetStreem(context.TODO())
+	assert.True(t, ts.EnableLineEditor)
+
+	// stdin is not a terminal in tests, so commands are read a line at a time
+	ts.startLineEditor()
+	assert.Nil(t, ts.editor)
+	ts.stopLineEditor()
+
+	// the terminal is restored once, by whichever exit path gets there first
+	restored := 0
+	ts.restoreInput = func() error { restored++; return nil }
+	ts.stopLineEditor()
+	ts.stopLineEditor()
+	assert.Equal(t, 1, restored)
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// the kinds of mute filter
+const (
+	FilterKeyword  = "keyword"  // the text contains the value, case insensitive
+	FilterRegex    = "regex"    // the text matches the regular expression
+	FilterUser     = "user"     // by the user, or retweeted by them
+	FilterApp      = "app"      // posted from the client app, eg: 'Twitter Web App'
+	FilterLang     = "lang"     // in the language, eg: 'en'
+	FilterRetweets = "retweets" // all retweets
+	FilterNoLinks  = "nolinks"  // tweets without a link, photo or video
+)
+
+var filterKinds = []string{FilterKeyword, FilterRegex, FilterUser, FilterApp, FilterLang, FilterRetweets, FilterNoLinks}
+
+// Filter mutes the tweets that match it, muted tweets are not shown.
+type Filter struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value,omitempty"`
+
+	re     *regexp.Regexp
+	hidden int // the number of tweets muted since startup
+}
+
+// NewFilter creates a filter, validating the kind and value.
+func NewFilter(kind, value string) (*Filter, error) {
+	f := &Filter{Kind: strings.ToLower(kind), Value: value}
+	if err := f.compile(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// compile validates the filter, and compiles the regular expression of a regex filter.
+func (f *Filter) compile() error {
+	switch f.Kind {
+	case FilterRetweets, FilterNoLinks:
+		if f.Value != "" {
+			return fmt.Errorf("the %s filter does not take a value", f.Kind)
+		}
+		return nil
+	case FilterKeyword, FilterUser, FilterApp, FilterLang:
+	case FilterRegex:
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %w", f.Value, err)
+		}
+		f.re = re
+	default:
+		return fmt.Errorf("invalid filter %q, expected one of %s", f.Kind, strings.Join(filterKinds, ", "))
+	}
+	if f.Value == "" {
+		return fmt.Errorf("the %s filter requires a value", f.Kind)
+	}
+	return nil
+}
+
+// Matches reports whether the filter mutes the tweet.
+func (f *Filter) Matches(tw *twitter.Tweet) bool {
+	orig := tw.Original()
+	switch f.Kind {
+	case FilterKeyword:
+		return strings.Contains(strings.ToLower(tweetText(orig)), strings.ToLower(f.Value))
+	case FilterRegex:
+		return f.re != nil && f.re.MatchString(tweetText(orig))
+	case FilterUser:
+		name := strings.TrimPrefix(f.Value, "@")
+		return strings.EqualFold(orig.User.ScreenName, name) || strings.EqualFold(tw.User.ScreenName, name)
+	case FilterApp:
+		return strings.EqualFold(util.ExtractAnchorText(orig.Source), f.Value)
+	case FilterLang:
+		return orig.Lang != nil && strings.EqualFold(*orig.Lang, f.Value)
+	case FilterRetweets:
+		return tw.ReTweetedStatus != nil
+	case FilterNoLinks:
+		return len(orig.Links()) == 0
+	}
+	return false
+}
+
+func (f *Filter) String() string {
+	if f.Value == "" {
+		return f.Kind
+	}
+	return fmt.Sprintf("%s %q", f.Kind, f.Value)
+}
+
+// tweetText returns the unescaped text of the tweet.
+func tweetText(tw *twitter.Tweet) string {
+	if tw.FullText != "" {
+		return html.UnescapeString(tw.FullText)
+	}
+	return html.UnescapeString(tw.Text)
+}
+
+// muted returns whether any filter mutes the tweet, and counts it against the first filter that does.
+func (t *TweetStreem) muted(tw *twitter.Tweet) bool {
+	t.filterLock.Lock()
+	defer t.filterLock.Unlock()
+	for _, f := range t.Filters {
+		if f.Matches(tw) {
+			f.hidden++
+			return true
+		}
+	}
+	return false
+}
+
+// compileFilters validates the configured filters.
+func (t *TweetStreem) compileFilters() error {
+	for _, f := range t.Filters {
+		f.Kind = strings.ToLower(f.Kind)
+		if err := f.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TweetStreem) commandFilter(args ...string) error {
+	if len(args) == 0 || args[0] == "" {
+		return t.listFilters()
+	}
+	switch strings.ToLower(args[0]) {
+	case "ls", "list":
+		return t.listFilters()
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf("a filter is required, eg: 'filter add keyword spoilers'")
+		}
+		value := ""
+		if len(args) > 2 {
+			value = args[2]
+		}
+		f, err := NewFilter(args[1], value)
+		if err != nil {
+			return err
+		}
+		t.filterLock.Lock()
+		t.Filters = append(t.Filters, f)
+		t.filterLock.Unlock()
+		t.println("added filter:", f)
+	case "rm", "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("a filter index is required, eg: 'filter rm 0'")
+		}
+		f, err := t.removeFilter(args[1])
+		if err != nil {
+			return err
+		}
+		t.println("removed filter:", f)
+	case "test":
+		ref, ok := firstTweetRef(args[1:]...)
+		if !ok {
+			return fmt.Errorf("invalid tweet id")
+		}
+		tw, err := t.lookupTweet(ref)
+		if err != nil {
+			return err
+		}
+		t.print(t.testFilters(tw))
+	default:
+		return fmt.Errorf("unknown filter command %q, expected add, ls, rm or test", args[0])
+	}
+	return nil
+}
+
+func (t *TweetStreem) removeFilter(index string) (*Filter, error) {
+	t.filterLock.Lock()
+	defer t.filterLock.Unlock()
+	idx, err := strconv.Atoi(index)
+	if err != nil || idx < 0 || idx >= len(t.Filters) {
+		return nil, fmt.Errorf("could not find filter for index: %s", index)
+	}
+	f := t.Filters[idx]
+	t.Filters = append(t.Filters[:idx], t.Filters[idx+1:]...)
+	return f, nil
+}
+
+func (t *TweetStreem) listFilters() error {
+	t.filterLock.Lock()
+	out := ""
+	for i, f := range t.Filters {
+		out += fmt.Sprintf("%d: %s (hidden: %d)\n", i, f, f.hidden)
+	}
+	t.filterLock.Unlock()
+	if out == "" {
+		out = "no filters\n"
+	}
+	t.print(out)
+	return nil
+}
+
+// testFilters lists the filters that would mute the tweet, without counting it.
+func (t *TweetStreem) testFilters(tw *twitter.Tweet) string {
+	t.filterLock.Lock()
+	defer t.filterLock.Unlock()
+	out := ""
+	for i, f := range t.Filters {
+		if f.Matches(tw) {
+			out += fmt.Sprintf("%d: %s\n", i, f)
+		}
+	}
+	if out == "" {
+		return fmt.Sprintf("tweet by @%s is not muted\n", tw.User.ScreenName)
+	}
+	return fmt.Sprintf("tweet by @%s is muted by:\n%s", tw.User.ScreenName, out)
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	lang := "fr"
+	tweet := &twitter.Tweet{
+		Text:   "Big game tonight &amp; spoilers ahead",
+		User:   twitter.User{ScreenName: "SportsFan"},
+		Source: `<a href="https://example.com" rel="nofollow">Buffer</a>`,
+		Lang:   &lang,
+	}
+	retweet := &twitter.Tweet{User: twitter.User{ScreenName: "friend"}, ReTweetedStatus: tweet}
+	linked := &twitter.Tweet{Text: "read this", Entities: twitter.Entities{Urls: []twitter.URL{{ExpandedURL: "https://example.com"}}}}
+
+	tests := []struct {
+		name  string
+		kind  string
+		value string
+		tweet *twitter.Tweet
+		want  bool
+	}{
+		{"keyword", FilterKeyword, "SPOILERS", tweet, true},
+		{"keyword unescaped", FilterKeyword, "& spoilers", tweet, true},
+		{"keyword no match", FilterKeyword, "football", tweet, false},
+		{"keyword retweet", FilterKeyword, "spoilers", retweet, true},
+		{"regex", FilterRegex, `(?i)^big \w+`, tweet, true},
+		{"regex no match", FilterRegex, `^game`, tweet, false},
+		{"user", FilterUser, "@sportsfan", tweet, true},
+		{"user retweeter", FilterUser, "friend", retweet, true},
+		{"user no match", FilterUser, "friend", tweet, false},
+		{"app", FilterApp, "buffer", tweet, true},
+		{"app no match", FilterApp, "Twitter Web App", tweet, false},
+		{"lang", FilterLang, "FR", tweet, true},
+		{"lang no match", FilterLang, "en", tweet, false},
+		{"lang unknown", FilterLang, "en", linked, false},
+		{"retweets", FilterRetweets, "", retweet, true},
+		{"retweets no match", FilterRetweets, "", tweet, false},
+		{"no links", FilterNoLinks, "", tweet, true},
+		{"no links no match", FilterNoLinks, "", linked, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := NewFilter(test.kind, test.value)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.want, f.Matches(test.tweet))
+		})
+	}
+}
+
+func TestNewFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		value   string
+		wantErr string
+	}{
+		{"kind", "colour", "red", `invalid filter "colour", expected one of keyword, regex, user, app, lang, retweets, nolinks`},
+		{"missing value", "keyword", "", "the keyword filter requires a value"},
+		{"unexpected value", "retweets", "all", "the retweets filter does not take a value"},
+		{"regex", "regex", "(", "invalid regex \"(\": error parsing regexp: missing closing ): `(`"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewFilter(test.kind, test.value)
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Filter(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	tw.EnableArchive = false
+	tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tw.ProcessCommand("filter"))
+	verifyPrint(t, tw, "no filters\n")
+	assert.NoError(t, tw.ProcessCommand(`filter add keyword "big game"`))
+	verifyPrint(t, tw, "added filter: keyword \"big game\"\n")
+	assert.NoError(t, tw.ProcessCommand("filter add user @muted"))
+	verifyPrint(t, tw, "added filter: user \"@muted\"\n")
+	assert.NoError(t, tw.ProcessCommand("filter add Retweets"))
+	verifyPrint(t, tw, "added filter: retweets\n")
+
+	shown := &twitter.Tweet{IDStr: "1", Text: "hello", User: twitter.User{ScreenName: "shown"}}
+	tw.PrintTweets([]*twitter.Tweet{
+		{IDStr: "4", ReTweetedStatus: shown},
+		{IDStr: "3", Text: "the big game", User: twitter.User{ScreenName: "sports"}},
+		{IDStr: "2", Text: "hello", User: twitter.User{ScreenName: "muted"}},
+		shown,
+	})
+	verifyPrint(t, tw, "1:shown\n")
+	verifyPrint(t, tw, "3 tweets hidden by filters, see 'filter ls'\n")
+	assert.Equal(t, 1, tw.tweetHistory.LastIdx(), "muted tweets are not added to the history")
+
+	assert.NoError(t, tw.ProcessCommand("filter ls"))
+	verifyPrint(t, tw, "0: keyword \"big game\" (hidden: 1)\n1: user \"@muted\" (hidden: 1)\n2: retweets (hidden: 1)\n")
+
+	assert.NoError(t, tw.ProcessCommand("filter test 1"))
+	verifyPrint(t, tw, "tweet by @shown is not muted\n")
+	tw.tweetHistory.Log(&twitter.Tweet{IDStr: "5", Text: "big game", User: twitter.User{ScreenName: "muted"}})
+	assert.NoError(t, tw.ProcessCommand("filter test 2"))
+	verifyPrint(t, tw, "tweet by @muted is muted by:\n0: keyword \"big game\"\n1: user \"@muted\"\n")
+
+	assert.NoError(t, tw.ProcessCommand("filter rm 1"))
+	verifyPrint(t, tw, "removed filter: user \"@muted\"\n")
+	assert.Len(t, tw.Filters, 2)
+
+	assert.EqualError(t, tw.ProcessCommand("filter rm 5"), "could not find filter for index: 5")
+	assert.EqualError(t, tw.ProcessCommand("filter rm"), "a filter index is required, eg: 'filter rm 0'")
+	assert.EqualError(t, tw.ProcessCommand("filter add"), "a filter is required, eg: 'filter add keyword spoilers'")
+	assert.EqualError(t, tw.ProcessCommand("filter add keyword"), "the keyword filter requires a value")
+	assert.EqualError(t, tw.ProcessCommand("filter test"), "invalid tweet id")
+	assert.EqualError(t, tw.ProcessCommand("filter clear"), `unknown filter command "clear", expected add, ls, rm or test`)
+}
+
+func TestTweetStreem_ProcessCommand_FilterRegex(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	assert.NoError(t, tw.ProcessCommand(`filter add regex \d+`))
+	verifyPrint(t, tw, "added filter: regex \"\\\\d+\"\n")
+	if assert.Len(t, tw.Filters, 1) {
+		assert.Equal(t, `\d+`, tw.Filters[0].Value, "the backslash is kept")
+		assert.True(t, tw.Filters[0].Matches(&twitter.Tweet{Text: "route 66"}))
+	}
+}
+
+func TestTweetStreem_CompileFilters(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	tw.Filters = []*Filter{{Kind: "REGEX", Value: "^go"}}
+	assert.NoError(t, tw.compileFilters())
+	assert.True(t, tw.Filters[0].Matches(&twitter.Tweet{Text: "gopher"}))
+
+	tw.Filters = append(tw.Filters, &Filter{Kind: "regex", Value: "("})
+	assert.Error(t, tw.compileFilters())
+}
+
+func TestTweetStreem_PrintTweets_HiddenByFilters(t *testing.T) {
+	muted := &twitter.Tweet{IDStr: "1", Text: "hello", User: twitter.User{ScreenName: "muted"}}
+	tests := []struct {
+		name   string
+		tweets []*twitter.Tweet
+		alert  bool
+		want   []string
+	}{
+		{"listing", []*twitter.Tweet{muted}, false, []string{"1 tweet hidden by filters, see 'filter ls'\n"}},
+		{"stream", []*twitter.Tweet{muted}, true, nil},
+		{"nothing hidden", []*twitter.Tweet{{IDStr: "2", User: twitter.User{ScreenName: "shown"}}}, false, []string{"1:shown\n"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tw := NewTweetStreem(context.TODO())
+			tw.EnableArchive = false
+			tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+			assert.NoError(t, tw.parseTemplate())
+			filter, err := NewFilter(FilterUser, "@muted")
+			assert.NoError(t, err)
+			tw.Filters = []*Filter{filter}
+
+			assert.Equal(t, test.want, collectPrints(tw, func() { tw.printTweets(test.tweets, test.alert) }))
+		})
+	}
+}
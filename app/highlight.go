@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// the kinds of highlight rule
+const (
+	HighlightText    = "text"    // the text contains the value, case insensitive
+	HighlightAuthor  = "author"  // by the user
+	HighlightMention = "mention" // mentions you
+	HighlightHashtag = "hashtag" // has the hashtag
+)
+
+var highlightKinds = []string{HighlightText, HighlightAuthor, HighlightMention, HighlightHashtag}
+
+// DefaultHighlightPrefix marks each line of a highlighted tweet.
+const DefaultHighlightPrefix = "┃ "
+
+// HighlightRule makes the tweets that match it stand out, and optionally alerts when they are streamed.
+type HighlightRule struct {
+	Name   string `json:"name,omitempty"`   // shown in notifications, defaults to the rule itself
+	Match  string `json:"match"`            // one of text, author, mention or hashtag
+	Value  string `json:"value,omitempty"`  // the text, screen name or hashtag, not used by mention
+	Color  string `json:"color,omitempty"`  // the color spec of the prefix
+	Prefix string `json:"prefix,omitempty"` // defaults to DefaultHighlightPrefix
+	Bell   bool   `json:"bell,omitempty"`   // ring the terminal bell
+	Notify bool   `json:"notify,omitempty"` // show a desktop notification
+}
+
+// validate checks the kind, value and color of the rule.
+func (r *HighlightRule) validate() error {
+	r.Match = strings.ToLower(r.Match)
+	if err := r.validateMatch(); err != nil {
+		return err
+	}
+	return util.ValidColor(r.Color)
+}
+
+// Matches reports whether the rule highlights the tweet, screenName is the authenticated user.
+func (r *HighlightRule) Matches(tw *twitter.Tweet, screenName string) bool {
+	orig := tw.Original()
+	switch r.Match {
+	case HighlightText:
+		return tweetContains(orig, r.Value)
+	case HighlightAuthor:
+		return tweetByAuthor(orig, r.Value)
+	case HighlightMention:
+		return tweetMentions(orig, screenName)
+	case HighlightHashtag:
+		return tweetHasHashtag(orig, r.Value)
+	}
+	return false
+}
+
+// validateMatch checks the kind and value of the rule's match.
+func (r *HighlightRule) validateMatch() error {
+	switch r.Match {
+	case HighlightMention:
+	case HighlightText, HighlightAuthor, HighlightHashtag:
+		if r.Value == "" {
+			return fmt.Errorf("the %s highlight rule requires a value", r.Match)
+		}
+	default:
+		return fmt.Errorf("invalid highlight rule %q, expected one of %s", r.Match, strings.Join(highlightKinds, ", "))
+	}
+	return nil
+}
+
+// tweetContains reports whether the text of the tweet contains the value, case insensitive.
+func tweetContains(tw *twitter.Tweet, value string) bool {
+	return strings.Contains(strings.ToLower(tweetText(tw)), strings.ToLower(value))
+}
+
+// tweetByAuthor reports whether the tweet is by the screen name, with or without the '@'.
+func tweetByAuthor(tw *twitter.Tweet, screenName string) bool {
+	return strings.EqualFold(tw.User.ScreenName, strings.TrimPrefix(screenName, "@"))
+}
+
+// tweetMentions reports whether the tweet mentions the screen name.
+func tweetMentions(tw *twitter.Tweet, screenName string) bool {
+	for _, um := range tw.Entities.UserMention {
+		if screenName != "" && strings.EqualFold(um.ScreenName, screenName) {
+			return true
+		}
+	}
+	return false
+}
+
+// tweetHasHashtag reports whether the tweet has the hashtag, with or without the '#'.
+func tweetHasHashtag(tw *twitter.Tweet, hashtag string) bool {
+	for _, ht := range tw.Entities.HashTags {
+		if strings.EqualFold(ht.Text, strings.TrimPrefix(hashtag, "#")) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply marks each line of the tweet output with the rule's prefix.
+func (r *HighlightRule) apply(out string) string {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = DefaultHighlightPrefix
+	}
+	if r.Color != "" {
+		prefix = util.Colors.Colorize(r.Color, prefix)
+	}
+	lines := strings.SplitAfter(out, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func (r *HighlightRule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if r.Value == "" {
+		return r.Match
+	}
+	return fmt.Sprintf("%s %s", r.Match, r.Value)
+}
+
+// validateHighlightRules validates the configured highlight rules.
+func (t *TweetStreem) validateHighlightRules() error {
+	for _, r := range t.HighlightRules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// highlightRule returns the first rule that matches the tweet, or nil if none do.
+func (t *TweetStreem) highlightRule(tw *twitter.Tweet) *HighlightRule {
+	for _, r := range t.HighlightRules {
+		if r.Matches(tw, t.TemplateOutputConfig.ScreenName) {
+			return r
+		}
+	}
+	return nil
+}
+
+// highlight applies the first matching rule to the tweet output, streamed tweets also ring the bell and notify.
+func (t *TweetStreem) highlight(tw *twitter.Tweet, out string, alert bool) string {
+	r := t.highlightRule(tw)
+	if r == nil {
+		return out
+	}
+	out = r.apply(out)
+	if !alert {
+		return out
+	}
+	if r.Bell {
+		out += "\a"
+	}
+	if r.Notify {
+		orig := tw.Original()
+		msg := fmt.Sprintf("@%s: %s", orig.User.ScreenName, tweetText(orig))
+		if err := util.DesktopNotifier.Notify("tweetstreem: "+r.String(), msg); err != nil {
+			out += fmt.Sprintln("Error: failed to notify:", err)
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/twitter/mocks"
+	"github.com/Setheck/tweetstreem/util"
+	mocks2 "github.com/Setheck/tweetstreem/util/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHighlightRule_Matches(t *testing.T) {
+	tweet := &twitter.Tweet{
+		Text: "Go 1.20 is out, thanks @Me #golang",
+		User: twitter.User{ScreenName: "golang"},
+		Entities: twitter.Entities{
+			HashTags:    []twitter.HashTag{{Text: "GoLang"}},
+			UserMention: []twitter.UserMention{{ScreenName: "me"}},
+		},
+	}
+	retweet := &twitter.Tweet{User: twitter.User{ScreenName: "friend"}, ReTweetedStatus: tweet}
+
+	tests := []struct {
+		name       string
+		rule       HighlightRule
+		tweet      *twitter.Tweet
+		screenName string
+		want       bool
+	}{
+		{"text", HighlightRule{Match: HighlightText, Value: "GO 1.20"}, tweet, "", true},
+		{"text no match", HighlightRule{Match: HighlightText, Value: "rust"}, tweet, "", false},
+		{"text retweet", HighlightRule{Match: HighlightText, Value: "go 1.20"}, retweet, "", true},
+		{"author", HighlightRule{Match: HighlightAuthor, Value: "@GoLang"}, tweet, "", true},
+		{"author retweet", HighlightRule{Match: HighlightAuthor, Value: "golang"}, retweet, "", true},
+		{"author no match", HighlightRule{Match: HighlightAuthor, Value: "friend"}, retweet, "", false},
+		{"mention", HighlightRule{Match: HighlightMention}, tweet, "ME", true},
+		{"mention other", HighlightRule{Match: HighlightMention}, tweet, "you", false},
+		{"mention unknown user", HighlightRule{Match: HighlightMention}, tweet, "", false},
+		{"hashtag", HighlightRule{Match: HighlightHashtag, Value: "#golang"}, tweet, "", true},
+		{"hashtag no match", HighlightRule{Match: HighlightHashtag, Value: "rust"}, tweet, "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.NoError(t, test.rule.validate())
+			assert.Equal(t, test.want, test.rule.Matches(test.tweet, test.screenName))
+		})
+	}
+}
+
+func TestHighlightRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    HighlightRule
+		wantErr string
+	}{
+		{"kind", HighlightRule{Match: "user", Value: "me"}, `invalid highlight rule "user", expected one of text, author, mention, hashtag`},
+		{"missing value", HighlightRule{Match: "Text"}, "the text highlight rule requires a value"},
+		{"color", HighlightRule{Match: "mention", Color: "shiny"}, `invalid color "shiny"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, test.rule.validate(), test.wantErr)
+		})
+	}
+}
+
+func TestHighlightRule_Apply(t *testing.T) {
+	depthSave := util.TerminalColorDepth
+	defer func() { util.TerminalColorDepth = depthSave }()
+	util.TerminalColorDepth = util.ColorDepthBasic
+
+	tests := []struct {
+		name string
+		rule HighlightRule
+		want string
+	}{
+		{"default prefix", HighlightRule{}, "\n┃ first\n┃ second\n"},
+		{"prefix", HighlightRule{Prefix: "! "}, "\n! first\n! second\n"},
+		{"color", HighlightRule{Prefix: "* ", Color: "red"}, "\n\033[31m* \033[0mfirst\n\033[31m* \033[0msecond\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.rule.apply("\nfirst\nsecond\n"))
+		})
+	}
+}
+
+func TestHighlightRule_String(t *testing.T) {
+	assert.Equal(t, "release", (&HighlightRule{Name: "release", Match: HighlightText, Value: "v2"}).String())
+	assert.Equal(t, "text v2", (&HighlightRule{Match: HighlightText, Value: "v2"}).String())
+	assert.Equal(t, "mention", (&HighlightRule{Match: HighlightMention}).String())
+}
+
+func newHighlightTweetStreem(t *testing.T, rules ...*HighlightRule) *TweetStreem {
+	tw := NewTweetStreem(context.TODO())
+	tw.EnableArchive = false
+	tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+	tw.HighlightRules = rules
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	return tw
+}
+
+func TestTweetStreem_PrintTweets_Highlight(t *testing.T) {
+	notifySave := util.DesktopNotifier
+	defer func() { util.DesktopNotifier = notifySave }()
+	notifyMock := new(mocks2.Notifier)
+	util.DesktopNotifier = notifyMock
+
+	tw := newHighlightTweetStreem(t,
+		&HighlightRule{Match: HighlightAuthor, Value: "friend", Prefix: "> ", Bell: true, Notify: true},
+		&HighlightRule{Match: HighlightText, Value: "go", Prefix: "# "},
+	)
+	tw.PrintTweets([]*twitter.Tweet{
+		{IDStr: "3", Text: "rust", User: twitter.User{ScreenName: "other"}},
+		{IDStr: "2", Text: "go", User: twitter.User{ScreenName: "friend"}},
+		{IDStr: "1", Text: "go", User: twitter.User{ScreenName: "gopher"}},
+	})
+	verifyPrint(t, tw, "# 1:gopher\n")
+	verifyPrint(t, tw, "> 2:friend\n")
+	verifyPrint(t, tw, "3:other\n")
+	notifyMock.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestTweetStreem_PollAndEcho_Alert(t *testing.T) {
+	notifySave := util.DesktopNotifier
+	defer func() { util.DesktopNotifier = notifySave }()
+
+	tests := []struct {
+		name      string
+		rule      *HighlightRule
+		notifyErr error
+		want      string
+	}{
+		{"highlight", &HighlightRule{Match: HighlightText, Value: "go"}, nil, "┃ 1:friend\n"},
+		{"bell", &HighlightRule{Match: HighlightText, Value: "go", Bell: true}, nil, "┃ 1:friend\n\a"},
+		{"notify", &HighlightRule{Name: "gophers", Match: HighlightText, Value: "go", Notify: true}, nil, "┃ 1:friend\n"},
+		{"notify error", &HighlightRule{Name: "gophers", Match: HighlightText, Value: "go", Notify: true}, assert.AnError,
+			"┃ 1:friend\nError: failed to notify: " + assert.AnError.Error() + "\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notifyMock := new(mocks2.Notifier)
+			if test.rule.Notify {
+				notifyMock.On("Notify", "tweetstreem: gophers", "@friend: go &  gophers").Return(test.notifyErr)
+			}
+			util.DesktopNotifier = notifyMock
+
+			twitterMock := new(mocks.Client)
+			twitterMock.On("StartPoller", mock.Anything).Run(func(args mock.Arguments) {
+				ch := args.Get(0).(chan<- []*twitter.Tweet)
+				go func() {
+					ch <- []*twitter.Tweet{{IDStr: "1", Text: "go &amp;  gophers", User: twitter.User{ScreenName: "friend"}}}
+					close(ch)
+				}()
+			})
+			tw := newHighlightTweetStreem(t, test.rule)
+			tw.twitter = twitterMock
+
+			tw.pollAndEcho()
+			verifyPrint(t, tw, test.want)
+			notifyMock.AssertExpectations(t)
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
@@ -2,41 +2,127 @@ package app
 
 import (
 	"sync"
-	"sync/atomic"
+
+	"github.com/Setheck/tweetstreem/twitter"
 )
 
-// History is a very simple tweet history that allows retrieval by index.
+// DefaultHistorySize is the number of tweets kept in the history.
+const DefaultHistorySize = 1000
+
+// History is a bounded tweet history, tweets are given increasing ids as they are logged and
+// can be retrieved by id or by tweet id. Once full, the oldest tweets are dropped.
 type History struct {
-	lastIdx *int32
-	history sync.Map
+	tweets  []*twitter.Tweet // a ring buffer, the tweet with id n is at (n-1) % len(tweets)
+	lastIdx int
+	ids     map[string]int // tweet id to history id
+	lock    sync.RWMutex
+}
+
+// NewHistory creates a new instance that holds up to size tweets.
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = DefaultHistorySize
+	}
+	return &History{
+		tweets: make([]*twitter.Tweet, size),
+		ids:    make(map[string]int),
+	}
 }
 
-// NewHistory creates a new instance.
-func NewHistory() *History {
-	h := &History{}
-	li := int32(0)
-	h.lastIdx = &li
-	return h
+// Log writes the tweet into the history at the next id, and returns its id.
+// A tweet already in the history moves to the next id, so the id shown with it is always the newest.
+func (h *History) Log(tw *twitter.Tweet) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	// the id may belong to a retweet of this tweet, only a tweet with the same id is a duplicate
+	if idx, ok := h.ids[tw.IDStr]; ok && h.tweets[h.slot(idx)].IDStr == tw.IDStr {
+		h.unindex(h.tweets[h.slot(idx)], idx)
+		h.tweets[h.slot(idx)] = nil
+	}
+	h.lastIdx++
+	slot := h.slot(h.lastIdx)
+	if old := h.tweets[slot]; old != nil {
+		h.unindex(old, h.lastIdx-len(h.tweets))
+	}
+	h.tweets[slot] = tw
+	h.index(tw, h.lastIdx)
+	return h.lastIdx
 }
 
-// Log writes the object into the history at the next index.
-func (h *History) Log(obj interface{}) {
-	atomic.AddInt32(h.lastIdx, 1)
-	h.history.Store(atomic.LoadInt32(h.lastIdx), obj)
+// Last retrieves the tweet with the given id, tweets dropped from the history are not found.
+func (h *History) Last(idx int) (*twitter.Tweet, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	if !h.holds(idx) || h.tweets[h.slot(idx)] == nil {
+		return nil, false
+	}
+	return h.tweets[h.slot(idx)], true
 }
 
-// Last retrieves the object from the given index.
-func (h *History) Last(idx int) (interface{}, bool) {
-	return h.history.Load(int32(idx))
+// Lookup retrieves a tweet and its id by tweet id, a retweet is also found by the id of the original tweet.
+func (h *History) Lookup(idStr string) (*twitter.Tweet, int, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	idx, ok := h.ids[idStr]
+	if !ok {
+		return nil, 0, false
+	}
+	return h.tweets[h.slot(idx)], idx, true
 }
 
-// LastIdx returns the most recent index in the history.
+// LastIdx returns the most recent id in the history.
 func (h *History) LastIdx() int {
-	return int(atomic.LoadInt32(h.lastIdx))
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.lastIdx
+}
+
+// Recent calls fn with the tweets in the history and their ids, most recent first, until fn returns false.
+func (h *History) Recent(fn func(idx int, tw *twitter.Tweet) bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for idx := h.lastIdx; h.holds(idx); idx-- {
+		tw := h.tweets[h.slot(idx)]
+		if tw == nil {
+			continue // moved to a newer id when it was seen again
+		}
+		if !fn(idx, tw) {
+			return
+		}
+	}
+}
+
+// Size returns the number of tweets the history holds.
+func (h *History) Size() int {
+	return len(h.tweets)
+}
+
+// holds reports whether the id is still in the history.
+func (h *History) holds(idx int) bool {
+	return idx > 0 && idx <= h.lastIdx && idx > h.lastIdx-len(h.tweets)
+}
+
+func (h *History) slot(idx int) int {
+	return (idx - 1) % len(h.tweets)
+}
+
+// index maps the tweet id, and the original tweet id of a retweet unless it was seen itself, to the history id.
+func (h *History) index(tw *twitter.Tweet, idx int) {
+	if tw.IDStr != "" {
+		h.ids[tw.IDStr] = idx
+	}
+	if orig := tw.Original(); orig != tw && orig.IDStr != "" {
+		if _, ok := h.ids[orig.IDStr]; !ok {
+			h.ids[orig.IDStr] = idx
+		}
+	}
 }
 
-// Clear resets the history.
-func (h *History) Clear() {
-	atomic.StoreInt32(h.lastIdx, 0)
-	h.history = sync.Map{}
+// unindex removes the mappings to the history id of the dropped tweet.
+func (h *History) unindex(tw *twitter.Tweet, idx int) {
+	for _, idStr := range []string{tw.IDStr, tw.Original().IDStr} {
+		if h.ids[idStr] == idx {
+			delete(h.ids, idStr)
+		}
+	}
 }
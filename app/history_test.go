@@ -3,50 +3,177 @@ package app
 import (
 	"testing"
 
+	"github.com/Setheck/tweetstreem/twitter"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHistory(t *testing.T) {
-	history := NewHistory()
+	history := NewHistory(DefaultHistorySize)
 	assert.NotNil(t, history)
 	assert.Zero(t, history.LastIdx())
 	last, ok := history.Last(1)
 	assert.Nil(t, last)
 	assert.False(t, ok)
 
-	obj1 := struct{}{}
-	obj2 := struct{}{}
-	history.Log(obj1)
+	tw1 := &twitter.Tweet{IDStr: "100"}
+	tw2 := &twitter.Tweet{IDStr: "200"}
+	assert.Equal(t, 1, history.Log(tw1))
 	assert.Equal(t, 1, history.LastIdx())
-	history.Log(obj2)
+	assert.Equal(t, 2, history.Log(tw2))
 	assert.Equal(t, 2, history.LastIdx())
 
-	if obj, ok := history.Last(1); true {
-		assert.Equal(t, obj1, obj)
+	if tw, ok := history.Last(1); true {
+		assert.Equal(t, tw1, tw)
 		assert.True(t, ok)
 	}
 
-	if obj, ok := history.Last(2); true {
-		assert.Equal(t, obj2, obj)
+	if tw, ok := history.Last(2); true {
+		assert.Equal(t, tw2, tw)
 		assert.True(t, ok)
 	}
 
-	if obj, ok := history.Last(3); true {
-		assert.Nil(t, obj)
+	for _, idx := range []int{-1, 0, 3} {
+		tw, ok := history.Last(idx)
+		assert.Nil(t, tw)
 		assert.False(t, ok)
 	}
 
-	history.Clear()
+	if tw, idx, ok := history.Lookup("200"); true {
+		assert.Equal(t, tw2, tw)
+		assert.Equal(t, 2, idx)
+		assert.True(t, ok)
+	}
 
-	if obj, ok := history.Last(1); true {
-		assert.Nil(t, obj)
+	if tw, idx, ok := history.Lookup("300"); true {
+		assert.Nil(t, tw)
+		assert.Zero(t, idx)
 		assert.False(t, ok)
 	}
+}
 
-	if obj, ok := history.Last(2); true {
-		assert.Nil(t, obj)
-		assert.False(t, ok)
+func TestHistory_Duplicates(t *testing.T) {
+	history := NewHistory(DefaultHistorySize)
+	tw := &twitter.Tweet{IDStr: "100", FavoriteCount: 1}
+	updated := &twitter.Tweet{IDStr: "100", FavoriteCount: 2}
+	assert.Equal(t, 1, history.Log(tw))
+	assert.Equal(t, 2, history.Log(&twitter.Tweet{IDStr: "200"}))
+	assert.Equal(t, 3, history.Log(updated), "a tweet seen twice moves to the next id")
+	assert.Equal(t, 3, history.LastIdx())
+	_, ok := history.Last(1)
+	assert.False(t, ok, "the old id is freed")
+	found, idx, _ := history.Lookup("100")
+	assert.Equal(t, updated, found)
+	assert.Equal(t, 3, idx)
+
+	var ids []int
+	history.Recent(func(idx int, tw *twitter.Tweet) bool {
+		ids = append(ids, idx)
+		return true
+	})
+	assert.Equal(t, []int{3, 2}, ids)
+
+	// tweets without an id are never duplicates
+	assert.Equal(t, 4, history.Log(&twitter.Tweet{}))
+	assert.Equal(t, 5, history.Log(&twitter.Tweet{}))
+}
+
+func TestHistory_DuplicatesBounded(t *testing.T) {
+	history := NewHistory(3)
+	early := &twitter.Tweet{IDStr: "1"}
+	history.Log(early)
+	history.Log(&twitter.Tweet{IDStr: "2"})
+	history.Log(&twitter.Tweet{IDStr: "3"})
+	assert.Equal(t, 4, history.Log(early), "the tweet shown again gets the newest id")
+
+	// fill the history past its size, the re-logged tweet outlives the tweets logged after it first
+	history.Log(&twitter.Tweet{IDStr: "5"})
+	history.Log(&twitter.Tweet{IDStr: "6"})
+	tw, ok := history.Last(4)
+	if assert.True(t, ok) {
+		assert.Equal(t, early, tw)
 	}
+	_, idx, ok := history.Lookup("1")
+	assert.True(t, ok)
+	assert.Equal(t, 4, idx)
 
-	assert.Zero(t, history.LastIdx())
+	history.Log(&twitter.Tweet{IDStr: "7"})
+	_, ok = history.Last(4)
+	assert.False(t, ok)
+	_, _, ok = history.Lookup("1")
+	assert.False(t, ok)
+	tw, ok = history.Last(7)
+	if assert.True(t, ok) {
+		assert.Equal(t, "7", tw.IDStr)
+	}
+}
+
+func TestHistory_Retweets(t *testing.T) {
+	history := NewHistory(DefaultHistorySize)
+	original := &twitter.Tweet{IDStr: "100"}
+	retweet := &twitter.Tweet{IDStr: "200", ReTweetedStatus: original}
+
+	assert.Equal(t, 1, history.Log(retweet))
+	tw, idx, ok := history.Lookup("100")
+	assert.True(t, ok, "a retweet is found by the original tweet id")
+	assert.Equal(t, retweet, tw)
+	assert.Equal(t, 1, idx)
+
+	assert.Equal(t, 2, history.Log(original), "the original is not a duplicate of the retweet")
+	_, idx, _ = history.Lookup("100")
+	assert.Equal(t, 2, idx)
+	_, idx, _ = history.Lookup("200")
+	assert.Equal(t, 1, idx)
+}
+
+func TestHistory_Bounded(t *testing.T) {
+	history := NewHistory(3)
+	assert.Equal(t, 3, history.Size())
+	for _, idStr := range []string{"1", "2", "3", "4", "5"} {
+		history.Log(&twitter.Tweet{IDStr: idStr})
+	}
+	assert.Equal(t, 5, history.LastIdx())
+
+	for _, idx := range []int{1, 2} {
+		_, ok := history.Last(idx)
+		assert.False(t, ok, "id %d was dropped", idx)
+	}
+	for _, idx := range []int{3, 4, 5} {
+		tw, ok := history.Last(idx)
+		if assert.True(t, ok) {
+			assert.Equal(t, idx, int(tw.IDStr[0]-'0'))
+		}
+	}
+	_, _, ok := history.Lookup("1")
+	assert.False(t, ok)
+	_, idx, ok := history.Lookup("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5, idx)
+
+	// a dropped tweet seen again gets a new id
+	assert.Equal(t, 6, history.Log(&twitter.Tweet{IDStr: "1"}))
+}
+
+func TestNewHistory_DefaultSize(t *testing.T) {
+	assert.Equal(t, DefaultHistorySize, NewHistory(0).Size())
+}
+
+func TestHistory_Recent(t *testing.T) {
+	history := NewHistory(3)
+	for _, idStr := range []string{"1", "2", "3", "4"} {
+		history.Log(&twitter.Tweet{IDStr: idStr})
+	}
+	var ids []int
+	history.Recent(func(idx int, tw *twitter.Tweet) bool {
+		ids = append(ids, idx)
+		assert.Equal(t, idx, int(tw.IDStr[0]-'0'))
+		return true
+	})
+	assert.Equal(t, []int{4, 3, 2}, ids)
+
+	ids = nil
+	history.Recent(func(idx int, tw *twitter.Tweet) bool {
+		ids = append(ids, idx)
+		return len(ids) < 2
+	})
+	assert.Equal(t, []int{4, 3}, ids)
 }
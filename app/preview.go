@@ -0,0 +1,278 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register image formats for decoding
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+const (
+	DefaultPreviewMaxWidth  = 40      // columns
+	DefaultPreviewMaxHeight = 12      // rows
+	DefaultPreviewMaxBytes  = 5 << 20 // 5MB
+)
+
+// maxPreviewPixels is the largest image that is decoded, a small compressed image can be huge once decoded
+const maxPreviewPixels = 5000 * 5000
+
+// autoPreviewTimeout limits the downloads of the previews shown as tweets are printed
+const autoPreviewTimeout = 5 * time.Second
+
+// previews are downloaded to this directory, in the config path
+var previewCacheDir = ".tweetstreem_previews"
+
+// the least recently used previews are removed once the cache grows past this size
+var maxPreviewCacheBytes int64 = 50 << 20 // 50MB
+
+// PreviewConfig configures inline image previews of tweet media.
+type PreviewConfig struct {
+	Auto      bool   `json:"auto"`      // preview media as tweets are printed
+	Protocol  string `json:"protocol"`  // auto, kitty, iterm2, sixel or halfblock
+	MaxWidth  int    `json:"maxWidth"`  // in columns
+	MaxHeight int    `json:"maxHeight"` // in rows
+	MaxBytes  int64  `json:"maxBytes"`  // the largest image that will be downloaded
+}
+
+// test points
+var (
+	previewClient = &http.Client{Timeout: 10 * time.Second}
+
+	// previews are drawn with escape sequences, that need a terminal with colors
+	previewsSupported = func() bool { return util.ColorOutput() && util.IsTerminal(os.Stdout) }
+)
+
+var (
+	errPreviewTooLarge    = errors.New("image too large to preview")
+	errPreviewUnsupported = errors.New("previews need a terminal with colors")
+)
+
+func (t *TweetStreem) commandPreview(args ...string) error {
+	ref, ok := firstTweetRef(args...)
+	if !ok {
+		return fmt.Errorf("invalid tweet id")
+	}
+	tw, err := t.findTweet(ref)
+	if err != nil {
+		return err
+	}
+	media := tw.AllMedia()
+	if len(media) == 0 {
+		return fmt.Errorf("tweet has no media")
+	}
+	if len(args) > 1 {
+		idx, err := strconv.Atoi(args[1])
+		if err != nil || idx < 0 || idx >= len(media) {
+			return fmt.Errorf("could not find media for index: %s", args[1])
+		}
+		media = media[idx : idx+1]
+	}
+	if !previewsSupported() {
+		return errPreviewUnsupported
+	}
+	return t.previewMedia(t.ctx, media)
+}
+
+// autoPreviews renders the media of the tweets, a few at a time, so the previews can be printed with their tweets.
+// the stream waits at most autoPreviewTimeout for the downloads, previews that are not ready by then are skipped.
+// returns the previews for each tweet, or nil if the previews are off or the terminal cannot draw them.
+func (t *TweetStreem) autoPreviews(tweets []*twitter.Tweet) [][]string {
+	if !t.Preview.Auto || !previewsSupported() {
+		return nil
+	}
+	type job struct {
+		tweet, media int
+		m            twitter.Media
+	}
+	var jobs []job
+	previews := make([][]string, len(tweets))
+	for i, tw := range tweets {
+		media := tw.Original().AllMedia()
+		previews[i] = make([]string, len(media))
+		for j, m := range media {
+			jobs = append(jobs, job{i, j, m})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(t.ctx, autoPreviewTimeout)
+	defer cancel()
+	jobCh := make(chan job)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < batchWorkers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobCh {
+				out, err := t.renderPreview(ctx, j.m)
+				switch {
+				case ctx.Err() != nil:
+					out = "Error: preview timed out\n"
+				case err != nil:
+					out = fmt.Sprintln("Error:", err)
+				}
+				previews[j.tweet][j.media] = out
+			}
+		}()
+	}
+	for _, j := range jobs {
+		jobCh <- j
+	}
+	close(jobCh)
+	wg.Wait()
+	return previews
+}
+
+// previewMedia prints each of the media as an inline image.
+func (t *TweetStreem) previewMedia(ctx context.Context, media []twitter.Media) error {
+	for _, m := range media {
+		out, err := t.renderPreview(ctx, m)
+		if err != nil {
+			return err
+		}
+		t.print(out)
+	}
+	return nil
+}
+
+func (t *TweetStreem) renderPreview(ctx context.Context, m twitter.Media) (string, error) {
+	protocol, err := util.ParseImageProtocol(t.Preview.Protocol)
+	if err != nil {
+		return "", err
+	}
+	if protocol == util.ImageProtocolAuto {
+		protocol = util.DetectImageProtocol(os.Getenv)
+	}
+	cols, rows := t.Preview.MaxWidth, t.Preview.MaxHeight
+	if cols <= 0 {
+		cols = DefaultPreviewMaxWidth
+	}
+	if rows <= 0 {
+		rows = DefaultPreviewMaxHeight
+	}
+	img, err := t.fetchPreview(ctx, previewURL(m), cols, rows)
+	if err != nil {
+		return "", err
+	}
+	if width := util.TerminalWidth(); cols > width {
+		cols = width
+	}
+	return util.RenderImage(img, protocol, cols, rows)
+}
+
+// previewURL is the url of the small size of the media, for videos and gifs this is the thumbnail.
+func previewURL(m twitter.Media) string {
+	u := m.MediaURLHTTPS
+	if u == "" {
+		u = m.MediaURL
+	}
+	return u + "?name=small"
+}
+
+// fetchPreview returns a thumbnail of the image at the url, scaled to fit in cols x rows cells.
+// the thumbnails are cached, so an image is only downloaded once for each preview size.
+func (t *TweetStreem) fetchPreview(ctx context.Context, u string, cols, rows int) (image.Image, error) {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %dx%d", u, cols, rows)))
+	file := filepath.Join(configPath, previewCacheDir, hex.EncodeToString(sum[:16]))
+	if data, err := os.ReadFile(file); err == nil {
+		// an unreadable thumbnail is downloaded again
+		if img, err := png.Decode(bytes.NewReader(data)); err == nil {
+			now := timeNow()
+			_ = os.Chtimes(file, now, now) // recently used
+			return img, nil
+		}
+	}
+
+	maxBytes := t.Preview.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultPreviewMaxBytes
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	resp, err := previewClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, errPreviewTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, errPreviewTooLarge
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPreviewPixels {
+		return nil, errPreviewTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	thumb := util.Thumbnail(img, cols, rows)
+
+	// the cache is best effort, a preview is shown even if it cannot be saved
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, thumb); err == nil && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		if err := os.WriteFile(file, buf.Bytes(), 0600); err == nil {
+			prunePreviewCache(filepath.Dir(file))
+		}
+	}
+	return thumb, nil
+}
+
+// prunePreviewCache removes the least recently used previews until the cache is within its size.
+func prunePreviewCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	files := make([]os.FileInfo, 0, len(entries))
+	var size int64
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, info)
+			size += info.Size()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, f := range files {
+		if size <= maxPreviewCacheBytes {
+			return
+		}
+		if err := os.Remove(filepath.Join(dir, f.Name())); err == nil {
+			size -= f.Size()
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// previewServer serves a 2x2 png, counting the requests made.
+func previewServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for x := 0; x < 2; x++ {
+		img.SetRGBA(x, 0, color.RGBA{R: 255, A: 255})
+		img.SetRGBA(x, 1, color.RGBA{B: 255, A: 255})
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/missing.png":
+			http.NotFound(w, r)
+			return
+		case "/huge.png":
+			_, _ = w.Write(pngHeader(100_000, 100_000))
+			return
+		case "/large.png":
+			_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 800, 400)))
+			return
+		}
+		assert.Equal(t, "small", r.URL.Query().Get("name"))
+		_, _ = w.Write(buf.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// pngHeader returns the start of a png of the given size, enough to decode its dimensions.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12], ihdr[13] = 8, 6 // 8 bit rgba
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func newPreviewTweetStreem(t *testing.T, media ...twitter.Media) *TweetStreem {
+	t.Helper()
+	pathSave, supportedSave := configPath, previewsSupported
+	t.Cleanup(func() { configPath, previewsSupported = pathSave, supportedSave })
+	configPath = t.TempDir()
+	previewsSupported = func() bool { return true }
+	depthSave := util.TerminalColorDepth
+	t.Cleanup(func() { util.TerminalColorDepth = depthSave })
+	util.TerminalColorDepth = util.ColorDepthBasic
+
+	tw := NewTweetStreem(context.TODO())
+	tw.Preview.Protocol = "halfblock"
+	tw.tweetHistory.Log(&twitter.Tweet{
+		IDStr:            "123",
+		User:             twitter.User{ScreenName: "test"},
+		ExtendedEntities: twitter.Entities{Media: media},
+	})
+	return tw
+}
+
+func TestTweetStreem_ProcessCommand_Preview(t *testing.T) {
+	srv, requests := previewServer(t)
+	tw := newPreviewTweetStreem(t,
+		twitter.Media{Type: "photo", MediaURLHTTPS: srv.URL + "/one.png"},
+		twitter.Media{Type: "photo", MediaURLHTTPS: srv.URL + "/two.png"},
+	)
+	halfBlock := "\033[31;44m▀\033[31;44m▀\033[0m\n"
+
+	assert.NoError(t, tw.ProcessCommand("preview 1 1"))
+	verifyPrint(t, tw, halfBlock)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	files, _ := os.ReadDir(filepath.Join(configPath, previewCacheDir))
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, tw.ProcessCommand("preview 1"))
+	verifyPrint(t, tw, halfBlock)
+	verifyPrint(t, tw, halfBlock)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests), "the second image is cached")
+
+	assert.EqualError(t, tw.ProcessCommand("preview 1 2"), "could not find media for index: 2")
+	assert.EqualError(t, tw.ProcessCommand("preview"), "usage: preview <id> [idx]")
+	assert.Error(t, tw.ProcessCommand("preview 5"))
+}
+
+func TestTweetStreem_FetchPreview_Thumbnail(t *testing.T) {
+	srv, requests := previewServer(t)
+	tw := newPreviewTweetStreem(t)
+
+	img, err := tw.fetchPreview(context.TODO(), srv.URL+"/large.png", 10, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, image.Rect(0, 0, 80, 40), img.Bounds(), "scaled to fit 10x5 cells")
+
+	files, _ := os.ReadDir(filepath.Join(configPath, previewCacheDir))
+	if assert.Len(t, files, 1) {
+		data, err := os.ReadFile(filepath.Join(configPath, previewCacheDir, files[0].Name()))
+		assert.NoError(t, err)
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, 80, cfg.Width, "the thumbnail is cached, not the download")
+	}
+
+	img, err = tw.fetchPreview(context.TODO(), srv.URL+"/large.png", 10, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 80, 40), img.Bounds())
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests), "the thumbnail is read from the cache")
+
+	_, err = tw.fetchPreview(context.TODO(), srv.URL+"/large.png", 20, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests), "a new preview size is downloaded again")
+}
+
+func TestTweetStreem_ProcessCommand_PreviewErrors(t *testing.T) {
+	srv, _ := previewServer(t)
+	tests := []struct {
+		name    string
+		media   []twitter.Media
+		setup   func(tw *TweetStreem)
+		wantErr string
+	}{
+		{"no media", nil, nil, "tweet has no media"},
+		{"not found", []twitter.Media{{MediaURLHTTPS: srv.URL + "/missing.png"}}, nil,
+			"failed to download image: 404 Not Found"},
+		{"too large", []twitter.Media{{MediaURLHTTPS: srv.URL + "/one.png"}},
+			func(tw *TweetStreem) { tw.Preview.MaxBytes = 10 }, errPreviewTooLarge.Error()},
+		{"too many pixels", []twitter.Media{{MediaURLHTTPS: srv.URL + "/huge.png"}}, nil, errPreviewTooLarge.Error()},
+		{"unsupported terminal", []twitter.Media{{MediaURLHTTPS: srv.URL + "/one.png"}},
+			func(tw *TweetStreem) { previewsSupported = func() bool { return false } }, errPreviewUnsupported.Error()},
+		{"invalid protocol", []twitter.Media{{MediaURLHTTPS: srv.URL + "/one.png"}},
+			func(tw *TweetStreem) { tw.Preview.Protocol = "ascii" },
+			`invalid image protocol "ascii", expected one of auto, kitty, iterm2, sixel or halfblock`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tw := newPreviewTweetStreem(t, test.media...)
+			if test.setup != nil {
+				test.setup(tw)
+			}
+			assert.EqualError(t, tw.ProcessCommand("preview 1"), test.wantErr)
+		})
+	}
+}
+
+func TestTweetStreem_PrintTweets_AutoPreview(t *testing.T) {
+	srv, _ := previewServer(t)
+	tw := newPreviewTweetStreem(t)
+	tw.TweetTemplate = "{{ .Id }}:{{ .ScreenName }}\n"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	tweet := &twitter.Tweet{
+		IDStr:    "456",
+		User:     twitter.User{ScreenName: "test"},
+		Entities: twitter.Entities{Media: []twitter.Media{{MediaURLHTTPS: srv.URL + "/one.png"}}},
+	}
+
+	tw.PrintTweets([]*twitter.Tweet{tweet})
+	verifyPrint(t, tw, "2:test\n")
+
+	tw.Preview.Auto = true
+	plain := &twitter.Tweet{IDStr: "789", User: twitter.User{ScreenName: "plain"}}
+	tw.PrintTweets([]*twitter.Tweet{plain, tweet})
+	verifyPrint(t, tw, "3:test\n")
+	verifyPrint(t, tw, "\033[31;44m▀\033[31;44m▀\033[0m\n") // printed with its tweet, before the next one
+	verifyPrint(t, tw, "4:plain\n")
+
+	previewsSupported = func() bool { return false }
+	tw.PrintTweets([]*twitter.Tweet{tweet})
+	verifyPrint(t, tw, "5:test\n")
+	select {
+	case printed := <-tw.printCh:
+		t.Errorf("no preview is printed without a color terminal, got %q", printed)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPrunePreviewCache(t *testing.T) {
+	sizeSave := maxPreviewCacheBytes
+	defer func() { maxPreviewCacheBytes = sizeSave }()
+	maxPreviewCacheBytes = 25
+
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"oldest", "old", "new"} {
+		file := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(file, bytes.Repeat([]byte("x"), 10), 0600))
+		mtime := now.Add(time.Duration(i-3) * time.Hour)
+		assert.NoError(t, os.Chtimes(file, mtime, mtime))
+	}
+	prunePreviewCache(dir)
+
+	var names []string
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"new", "old"}, names, "the least recently used preview is removed")
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// templateHelper is a function available to the tweet template, along with its documentation.
+type templateHelper struct {
+	Name        string
+	Usage       string
+	Description string
+	Func        interface{}
+}
+
+// templateHelpers are the functions available to the tweet template, the README documentation
+// is generated from this list, see TestTemplateHelpersDoc.
+// helpers take the piped value as their last argument, eg: {{ .TweetText | truncate 40 }}
+var templateHelpers = []templateHelper{
+	{"color", `color <color> <text>`, "colorizes the text with a theme color name, or a color", util.Colors.Colorize},
+	// the time helpers with no func show times in the configured time zone, they are bound in templateFuncs
+	{"format", `format <createdAt> <go time layout>`, "formats a tweet time with the given layout, in the configured time zone", nil},
+	{"humantime", `humantime <createdAt>`, "the tweet time humanized, ex: `3m`, `yesterday 14:02`, `Mon 09:30`", nil},
+	{"rfc3339", `rfc3339 <createdAt>`, "the tweet time as RFC3339 in the configured time zone, ex: `2020-03-24T21:07:21-04:00`", nil},
+	{"iso8601", `iso8601 <createdAt>`, "the tweet time as ISO8601 in UTC, ex: `2020-03-25T01:07:21Z`, these sort in time order", iso8601},
+	{"truncate", `truncate <width> <text>`, "shortens the text to width characters, ending with `…`", truncateHelper},
+	{"wrap", `wrap <width> <text>`, "word wraps the text to lines of width columns, a width of 0 is the terminal width, or no wrapping when the output is not a terminal", wrapHelper},
+	{"wrapindent", `wrapindent <width> <indent> <text>`, "word wraps like `wrap`, indenting lines after the first with indent, ex: `wrapindent 0 \"  \"`", wrapIndentHelper},
+	{"pad", `pad <width> <text>`, "pads the text with spaces to width columns, a negative width right aligns", padHelper},
+	{"plural", `plural <singular> <plural> <count>`, "the count followed by the singular or plural word, ex: `2 replies`", plural},
+	{"humanize", `humanize <count>`, "the count in a short form, ex: `1.2k`", util.Humanize},
+	{"since", `since <createdAt>`, "the time since a tweet time, ex: `3m`, `5h`, `2d`", since},
+	{"emoji", `emoji <name>`, "the emoji for a name, one of " + emojiNames(), emoji},
+}
+
+// templateFuncs returns the template helpers, with color names resolved by the given theme,
+// and times shown as configured by the output config.
+func templateFuncs(theme Theme, config *twitter.OutputConfig) template.FuncMap {
+	funcs := make(template.FuncMap, len(templateHelpers))
+	for _, h := range templateHelpers {
+		funcs[h.Name] = h.Func
+	}
+	funcs["color"] = theme.Colorize
+	funcs["format"] = func(createdAt, layout string) string {
+		return formatCreatedAt(createdAt, layout, config.TimeLocation())
+	}
+	funcs["rfc3339"] = func(createdAt string) string {
+		return formatCreatedAt(createdAt, time.RFC3339, config.TimeLocation())
+	}
+	funcs["humantime"] = func(createdAt string) string {
+		humanized := *config
+		humanized.TimeFormat = twitter.TimeFormatHumanized
+		return (&twitter.Tweet{CreatedAt: createdAt}).FormatTime(humanized)
+	}
+	return funcs
+}
+
+// templateHelpersDoc returns the markdown documentation for the template helpers.
+func templateHelpersDoc() string {
+	var sb strings.Builder
+	for _, h := range templateHelpers {
+		sb.WriteString(fmt.Sprintf("* `%s` - %s\n", h.Usage, h.Description))
+	}
+	return sb.String()
+}
+
+func truncateHelper(width int, s string) string { return util.Truncate(s, width) }
+func wrapHelper(width int, s string) string     { return wrapIndentHelper(width, "", s) }
+func padHelper(width int, s string) string      { return util.Pad(s, width) }
+
+// test point, output that is piped or redirected is not wrapped to the terminal width
+var outputIsTerminal = func() bool { return util.IsTerminal(os.Stdout) }
+
+// wrapIndentHelper wraps to the terminal width when the width is not positive,
+// unless stdout is not a terminal, then the text is left as is.
+func wrapIndentHelper(width int, indent, s string) string {
+	if width <= 0 {
+		if !outputIsTerminal() {
+			return s
+		}
+		width = util.TerminalWidth()
+	}
+	return util.WrapIndent(s, width, indent)
+}
+
+func plural(singular, plural string, count int) string {
+	if count == 1 {
+		return fmt.Sprint(count, " ", singular)
+	}
+	return fmt.Sprint(count, " ", plural)
+}
+
+// test point
+var timeNow = time.Now
+
+func since(createdAt string) string {
+	tm, err := twitter.ParseCreatedAt(createdAt)
+	if err != nil {
+		return createdAt
+	}
+	return util.HumanizeDuration(timeNow().Sub(tm))
+}
+
+// formatCreatedAt formats a tweet time with the layout, in the given time zone.
+func formatCreatedAt(createdAt, layout string, loc *time.Location) string {
+	tm, err := twitter.ParseCreatedAt(createdAt)
+	if err != nil {
+		return createdAt
+	}
+	return tm.In(loc).Format(layout)
+}
+
+func iso8601(createdAt string) string {
+	return formatCreatedAt(createdAt, "2006-01-02T15:04:05Z", time.UTC)
+}
+
+var emojis = []struct{ name, emoji string }{
+	{"like", "♥"},
+	{"retweet", "↻"},
+	{"reply", "↩"},
+	{"quote", "❝"},
+	{"verified", "✓"},
+	{"link", "🔗"},
+	{"photo", "📷"},
+	{"video", "🎥"},
+	{"poll", "📊"},
+	{"location", "📍"},
+	{"lock", "🔒"},
+}
+
+func emoji(name string) string {
+	for _, e := range emojis {
+		if e.name == name {
+			return e.emoji
+		}
+	}
+	return ""
+}
+
+func emojiNames() string {
+	names := make([]string, len(emojis))
+	for i, e := range emojis {
+		names[i] = "`" + e.name + "`"
+	}
+	return strings.Join(names, ", ")
+}
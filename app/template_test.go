@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateDocs = flag.Bool("update-docs", false, "update the template helper docs in the README")
+
+const (
+	helpersDocStart = "<!-- template helpers -->\n"
+	helpersDocEnd   = "<!-- end template helpers -->"
+)
+
+// TestTemplateHelpersDoc verifies the README documents every template helper,
+// run `make docs` to regenerate it.
+func TestTemplateHelpersDoc(t *testing.T) {
+	readme := filepath.Join("..", "README.md")
+	data, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	before, rest, found := strings.Cut(content, helpersDocStart)
+	if !assert.True(t, found, "README is missing the template helpers marker") {
+		return
+	}
+	_, after, found := strings.Cut(rest, helpersDocEnd)
+	if !assert.True(t, found, "README is missing the end template helpers marker") {
+		return
+	}
+	want := before + helpersDocStart + templateHelpersDoc() + helpersDocEnd + after
+	if *updateDocs {
+		if err := os.WriteFile(readme, []byte(want), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	assert.Equal(t, want, content, "README template helpers are out of date, run: make docs")
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	funcs := templateFuncs(DefaultTheme(), &twitter.OutputConfig{})
+	assert.Len(t, funcs, len(templateHelpers))
+	for _, h := range templateHelpers {
+		assert.NotNil(t, funcs[h.Name], h.Name)
+		assert.Contains(t, templateHelpersDoc(), h.Usage)
+	}
+}
+
+func TestWrapIndentHelper(t *testing.T) {
+	terminalSave := outputIsTerminal
+	defer func() { outputIsTerminal = terminalSave }()
+	text := strings.Repeat("word ", 20)
+
+	tests := []struct {
+		name     string
+		terminal bool
+		width    int
+		want     string
+	}{
+		{"terminal width", true, 0, strings.TrimSpace(strings.Repeat("word ", 16)) + "\n" + strings.TrimSpace(strings.Repeat("word ", 4))},
+		{"not a terminal", false, 0, text},
+		{"not a terminal with a width", false, 50, strings.TrimSpace(strings.Repeat("word ", 10)) + "\n" + strings.TrimSpace(strings.Repeat("word ", 10))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputIsTerminal = func() bool { return test.terminal }
+			assert.Equal(t, test.want, wrapIndentHelper(test.width, "", text))
+		})
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	now := time.Date(2020, 3, 25, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	ago := func(d time.Duration) string { return now.Add(-d).Format(twitter.CreatedAtTimeLayout) }
+	terminalSave := outputIsTerminal
+	defer func() { outputIsTerminal = terminalSave }()
+	outputIsTerminal = func() bool { return true }
+
+	tests := []struct {
+		name     string
+		template string
+		data     interface{}
+		want     string
+	}{
+		{"truncate", `{{ . | truncate 5 }}`, "hello world", "hell…"},
+		{"truncate short", `{{ . | truncate 20 }}`, "hello world", "hello world"},
+		{"wrap", `{{ . | wrap 11 }}`, "the quick brown fox jumps", "the quick\nbrown fox\njumps"},
+		{"wrap terminal width", `{{ . | wrap 0 }}`, strings.Repeat("word ", 20),
+			strings.TrimSpace(strings.Repeat("word ", 16)) + "\n" + strings.TrimSpace(strings.Repeat("word ", 4))},
+		{"wrapindent", `text: {{ . | wrapindent 16 "      " }}`, "the quick brown fox jumps",
+			"text: the quick\n      brown fox\n      jumps"},
+		{"pad", `[{{ . | pad 6 }}]`, "abc", "[abc   ]"},
+		{"pad right align", `[{{ . | pad -6 }}]`, "abc", "[   abc]"},
+		{"plural one", `{{ . | plural "reply" "replies" }}`, 1, "1 reply"},
+		{"plural many", `{{ . | plural "reply" "replies" }}`, 3, "3 replies"},
+		{"plural none", `{{ . | plural "reply" "replies" }}`, 0, "0 replies"},
+		{"humanize", `{{ . | humanize }}`, 1234, "1.2k"},
+		{"since seconds", `{{ . | since }}`, ago(42 * time.Second), "42s"},
+		{"since minutes", `{{ . | since }}`, ago(3 * time.Minute), "3m"},
+		{"since hours", `{{ . | since }}`, ago(5*time.Hour + 59*time.Minute), "5h"},
+		{"since days", `{{ . | since }}`, ago(50 * time.Hour), "2d"},
+		{"since years", `{{ . | since }}`, ago(800 * 24 * time.Hour), "2y"},
+		{"since invalid", `{{ . | since }}`, "not a time", "not a time"},
+		{"format", `{{ format . "2006-01-02 15:04" }}`, "Wed Mar 25 01:07:21 +0000 2020", "2020-03-24 21:07"},
+		{"rfc3339", `{{ . | rfc3339 }}`, "Wed Mar 25 01:07:21 +0000 2020", "2020-03-24T21:07:21-04:00"},
+		{"iso8601", `{{ . | iso8601 }}`, "Wed Mar 25 01:07:21 +0000 2020", "2020-03-25T01:07:21Z"},
+		{"time invalid", `{{ . | rfc3339 }}`, "not a time", "not a time"},
+		{"humantime", `{{ . | humantime }}`, time.Now().Add(-3 * time.Minute).Format(twitter.CreatedAtTimeLayout), "3m"},
+		{"emoji", `{{ emoji "verified" }}`, nil, "✓"},
+		{"emoji unknown", `{{ emoji "nope" }}`, nil, ""},
+		{"if verified", `{{ if .Verified }}{{ emoji "verified" }}{{ end }}{{ .User.ScreenName }}`,
+			twitter.TweetTemplateOutput{Verified: true, User: twitter.User{ScreenName: "test"}}, "✓test"},
+		{"counts", `{{ .Counts.Favorites | humanize }} {{ .Counts.Replies | plural "reply" "replies" }}`,
+			twitter.TweetTemplateOutput{Counts: twitter.TweetCounts{Favorites: 15300, Replies: 1}}, "15k 1 reply"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tw := NewTweetStreem(context.TODO())
+			tw.TemplateOutputConfig.Location = time.FixedZone("UTC-4", -4*60*60)
+			tw.TweetTemplate = test.template
+			if !assert.NoError(t, tw.parseTemplate()) {
+				return
+			}
+			var sb strings.Builder
+			assert.NoError(t, tw.tweetTemplate.Execute(&sb, test.data))
+			assert.Equal(t, test.want, sb.String())
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// the semantic color names themes define, used by templates eg: {{ .UserName | color "author" }}
+const (
+	colorAuthor     = "author"
+	colorScreenName = "screenname"
+	colorTime       = "time"
+	colorRetweet    = "retweet"
+	colorLike       = "like"
+	colorApp        = "app"
+	colorVerified   = "verified"
+	colorPrompt     = "prompt"
+	colorMention    = "mention"
+	colorSelf       = "self"
+	colorHashtag    = "hashtag"
+	colorCashtag    = "cashtag"
+	colorURL        = "url"
+	colorMedia      = "media"
+)
+
+// noColor disables coloring for a semantic color name in a theme
+const noColor = "none"
+
+// themes are loaded from json files in this directory, in the config path
+var themesDir = ".tweetstreem_themes"
+
+// Theme is a named palette, mapping semantic color names to terminal colors.
+type Theme struct {
+	Name   string            `json:"name"`
+	Colors map[string]string `json:"colors"`
+}
+
+// DefaultThemeName is the theme used when none is configured.
+const DefaultThemeName = "default"
+
+var builtinThemes = []Theme{
+	{Name: DefaultThemeName, Colors: map[string]string{
+		colorAuthor:     "cyan",
+		colorScreenName: "green",
+		colorTime:       "magenta",
+		colorRetweet:    "cyan",
+		colorLike:       "red",
+		colorApp:        "blue",
+		colorVerified:   "blue",
+		colorPrompt:     "red",
+		colorMention:    DefaultMentionHighlightColor,
+		colorSelf:       DefaultSelfMentionHighlightColor,
+		colorHashtag:    DefaultHashtagHighlightColor,
+		colorCashtag:    DefaultCashtagHighlightColor,
+		colorURL:        DefaultURLHighlightColor,
+		colorMedia:      DefaultMediaHighlightColor,
+	}},
+	{Name: "solarized", Colors: map[string]string{
+		colorAuthor:     "#b58900",
+		colorScreenName: "#2aa198",
+		colorTime:       "#586e75",
+		colorRetweet:    "#859900",
+		colorLike:       "#d33682",
+		colorApp:        "#268bd2",
+		colorVerified:   "#268bd2",
+		colorPrompt:     "#cb4b16",
+		colorMention:    "#268bd2",
+		colorSelf:       "#dc322f",
+		colorHashtag:    "#6c71c4",
+		colorCashtag:    "#859900",
+		colorURL:        "#2aa198",
+		colorMedia:      "#b58900",
+	}},
+	{Name: "mono", Colors: map[string]string{
+		colorAuthor:     noColor,
+		colorScreenName: noColor,
+		colorTime:       noColor,
+		colorRetweet:    noColor,
+		colorLike:       noColor,
+		colorApp:        noColor,
+		colorVerified:   noColor,
+		colorPrompt:     noColor,
+		colorMention:    noColor,
+		colorSelf:       noColor,
+		colorHashtag:    noColor,
+		colorCashtag:    noColor,
+		colorURL:        noColor,
+		colorMedia:      noColor,
+	}},
+	{Name: "high-contrast", Colors: map[string]string{
+		colorAuthor:     "bold white",
+		colorScreenName: "bold yellow",
+		colorTime:       "white",
+		colorRetweet:    "bold green",
+		colorLike:       "bold red",
+		colorApp:        "cyan",
+		colorVerified:   "bold cyan",
+		colorPrompt:     "bold yellow",
+		colorMention:    "bold yellow",
+		colorSelf:       "bold white on red",
+		colorHashtag:    "bold cyan",
+		colorCashtag:    "bold green",
+		colorURL:        "underline cyan",
+		colorMedia:      "yellow",
+	}},
+}
+
+// DefaultTheme returns a copy of the default theme.
+func DefaultTheme() Theme {
+	return builtinThemes[0].copy()
+}
+
+func (th Theme) copy() Theme {
+	colors := make(map[string]string, len(th.Colors))
+	for k, v := range th.Colors {
+		colors[k] = v
+	}
+	return Theme{Name: th.Name, Colors: colors}
+}
+
+// Color returns the terminal color for the given name, semantic names missing from the theme
+// fall back to the default theme, other names are returned as is so templates can use eg: "red".
+// an empty string means no color.
+func (th Theme) Color(name string) string {
+	color, ok := th.Colors[name]
+	if !ok {
+		color, ok = builtinThemes[0].Colors[name]
+	}
+	if !ok {
+		color = name
+	}
+	if color == noColor {
+		return ""
+	}
+	return color
+}
+
+// Colorize returns the text in the color for the given name.
+func (th Theme) Colorize(name, s string) string {
+	color := th.Color(name)
+	if color == "" {
+		return s
+	}
+	return util.Colors.Colorize(color, s)
+}
+
+// loadThemes returns the builtin themes and the themes from the themes directory,
+// a theme file overrides a builtin theme of the same name.
+func loadThemes() ([]Theme, error) {
+	themes := make(map[string]Theme, len(builtinThemes))
+	for _, th := range builtinThemes {
+		themes[th.Name] = th
+	}
+	files, err := filepath.Glob(filepath.Join(configPath, themesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		th, err := loadThemeFile(file)
+		if err != nil {
+			return nil, err
+		}
+		themes[th.Name] = th
+	}
+	list := make([]Theme, 0, len(themes))
+	for _, th := range themes {
+		list = append(list, th)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// loadThemeFile reads a theme from a json file, named after the file when no name is given.
+func loadThemeFile(file string) (Theme, error) {
+	var th Theme
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return th, fmt.Errorf("failed to read theme: %w", err)
+	}
+	if err := json.Unmarshal(data, &th); err != nil {
+		return th, fmt.Errorf("failed to parse theme %q: %w", file, err)
+	}
+	if th.Name == "" {
+		th.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	for name, color := range th.Colors {
+		if color == noColor {
+			continue
+		}
+		if err := util.ValidColor(color); err != nil {
+			return th, fmt.Errorf("theme %q color %q: %w", th.Name, name, err)
+		}
+	}
+	return th, nil
+}
+
+var errUnknownTheme = errors.New("unknown theme")
+
+func findTheme(name string) (Theme, error) {
+	themes, err := loadThemes()
+	if err != nil {
+		return Theme{}, err
+	}
+	for _, th := range themes {
+		if strings.EqualFold(th.Name, name) {
+			return th.copy(), nil
+		}
+	}
+	return Theme{}, fmt.Errorf("%w %q", errUnknownTheme, name)
+}
+
+func (t *TweetStreem) commandTheme(args ...string) error {
+	if len(args) == 0 || args[0] == "" {
+		themes, err := loadThemes()
+		if err != nil {
+			return err
+		}
+		active, out := t.theme().Name, ""
+		for _, th := range themes {
+			marker := " "
+			if th.Name == active {
+				marker = "*"
+			}
+			out += fmt.Sprintf("%s %s\n", marker, th.Name)
+		}
+		t.print(out)
+		return nil
+	}
+	th, err := findTheme(args[0])
+	if err != nil {
+		return err
+	}
+	if err := t.setTheme(th); err != nil {
+		return err
+	}
+	t.println("theme set to", th.Name)
+	return nil
+}
+
+// theme returns the active theme.
+func (t *TweetStreem) theme() Theme {
+	t.templateLock.RLock()
+	defer t.templateLock.RUnlock()
+	return t.Theme
+}
+
+// setTheme makes the given theme active, the highlight colors are taken from the theme.
+func (t *TweetStreem) setTheme(th Theme) error {
+	t.templateLock.Lock()
+	defer t.templateLock.Unlock()
+	t.Theme = th
+	t.TemplateOutputConfig.MentionHighlightColor = th.Color(colorMention)
+	t.TemplateOutputConfig.SelfMentionHighlightColor = th.Color(colorSelf)
+	t.TemplateOutputConfig.HashtagHighlightColor = th.Color(colorHashtag)
+	t.TemplateOutputConfig.CashtagHighlightColor = th.Color(colorCashtag)
+	t.TemplateOutputConfig.URLHighlightColor = th.Color(colorURL)
+	t.TemplateOutputConfig.MediaHighlightColor = th.Color(colorMedia)
+	return t.parseTemplate()
+}
@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Setheck/tweetstreem/app/mocks"
+	"github.com/Setheck/tweetstreem/twitter"
+	"github.com/Setheck/tweetstreem/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func withThemesDir(t *testing.T, files map[string]string) {
+	pathSave := configPath
+	t.Cleanup(func() { configPath = pathSave })
+	configPath = t.TempDir()
+	dir := filepath.Join(configPath, themesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTheme_Color(t *testing.T) {
+	theme := Theme{Name: "test", Colors: map[string]string{
+		colorAuthor: "yellow",
+		colorTime:   noColor,
+	}}
+	tests := []struct {
+		name  string
+		color string
+		want  string
+	}{
+		{"themed", colorAuthor, "yellow"},
+		{"disabled", colorTime, ""},
+		{"default fallback", colorLike, "red"},
+		{"raw color", "green", "green"},
+		{"empty", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, theme.Color(test.color))
+		})
+	}
+	assert.Equal(t, util.Colors.Colorize("yellow", "text"), theme.Colorize(colorAuthor, "text"))
+	assert.Equal(t, "text", theme.Colorize(colorTime, "text"))
+	assert.Equal(t, util.Colors.Colorize("cyan", "text"), Theme{}.Colorize(colorAuthor, "text"))
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	names := map[string]bool{}
+	for _, th := range builtinThemes {
+		names[th.Name] = true
+		// every builtin theme defines every color name
+		assert.Len(t, th.Colors, len(builtinThemes[0].Colors), th.Name)
+		for name := range builtinThemes[0].Colors {
+			assert.Contains(t, th.Colors, name, th.Name)
+		}
+	}
+	assert.Equal(t, map[string]bool{"default": true, "solarized": true, "mono": true, "high-contrast": true}, names)
+
+	// copies do not share colors
+	theme := DefaultTheme()
+	theme.Colors[colorAuthor] = "white"
+	assert.Equal(t, "cyan", DefaultTheme().Colors[colorAuthor])
+}
+
+func TestLoadThemes(t *testing.T) {
+	withThemesDir(t, map[string]string{
+		"ocean.json":   `{"colors": {"author": "blue", "time": "none", "url": "bold #00afff"}}`,
+		"named.json":   `{"name": "sunset", "colors": {"author": "red"}}`,
+		"mono.json":    `{"name": "mono", "colors": {"author": "white"}}`,
+		"readme.txt":   `not a theme`,
+		"ignored.yaml": `name: ignored`,
+	})
+	themes, err := loadThemes()
+	assert.NoError(t, err)
+	var names []string
+	for _, th := range themes {
+		names = append(names, th.Name)
+	}
+	assert.Equal(t, []string{"default", "high-contrast", "mono", "ocean", "solarized", "sunset"}, names)
+
+	ocean, err := findTheme("Ocean")
+	assert.NoError(t, err)
+	assert.Equal(t, "blue", ocean.Color(colorAuthor))
+	assert.Equal(t, "green", ocean.Color(colorScreenName))
+
+	mono, err := findTheme("mono")
+	assert.NoError(t, err)
+	assert.Equal(t, "white", mono.Color(colorAuthor), "theme files override builtin themes")
+
+	_, err = findTheme("missing")
+	assert.ErrorIs(t, err, errUnknownTheme)
+}
+
+func TestLoadThemes_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"bad json", `{"colors":`},
+		{"bad color", `{"colors": {"author": "purple"}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withThemesDir(t, map[string]string{"broken.json": test.content})
+			_, err := loadThemes()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Theme(t *testing.T) {
+	withThemesDir(t, map[string]string{"ocean.json": `{"colors": {"author": "blue", "mention": "cyan"}}`})
+
+	tw := NewTweetStreem(context.TODO())
+	assert.NoError(t, tw.parseTemplate())
+	assert.NoError(t, tw.ProcessCommand("theme"))
+	verifyPrint(t, tw, "* default\n  high-contrast\n  mono\n  ocean\n  solarized\n")
+
+	assert.NoError(t, tw.ProcessCommand("theme ocean"))
+	verifyPrint(t, tw, "theme set to ocean\n")
+	assert.Equal(t, "ocean", tw.Theme.Name)
+	assert.Equal(t, "cyan", tw.TemplateOutputConfig.MentionHighlightColor)
+	assert.Equal(t, DefaultHashtagHighlightColor, tw.TemplateOutputConfig.HashtagHighlightColor)
+
+	assert.Equal(t, "[0] "+util.Colors.Colorize("blue", "name")+" "+util.Colors.Colorize("green", "@name")+" followers:0\n",
+		tw.formatUser(0, twitter.User{Name: "name", ScreenName: "name"}))
+	tw.TweetTemplate = `{{ .UserName | color "author" }}`
+	assert.NoError(t, tw.parseTemplate())
+	tw.PrintTweets([]*twitter.Tweet{{User: twitter.User{Name: "name"}}})
+	verifyPrint(t, tw, util.Colors.Colorize("blue", "name"))
+
+	assert.NoError(t, tw.ProcessCommand("theme mono"))
+	verifyPrint(t, tw, "theme set to mono\n")
+	assert.Empty(t, tw.TemplateOutputConfig.MentionHighlightColor)
+	assert.Contains(t, tw.config(), `"theme":{"name":"mono","colors":{`, "the theme is saved with the config")
+
+	assert.Error(t, tw.ProcessCommand("theme missing"))
+	assert.Equal(t, "mono", tw.Theme.Name)
+}
+
+func TestTweetStreem_SetTheme_WhilePrinting(t *testing.T) {
+	withThemesDir(t, nil)
+	tw := NewTweetStreem(context.TODO())
+	tw.EnableArchive = false
+	tw.TweetTemplate = `{{ .UserName | color "author" }} {{ .TweetText }}`
+	assert.NoError(t, tw.parseTemplate())
+
+	// run with -race, the poller prints while a command switches the theme
+	printed := collectPrints(tw, func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				tw.PrintTweets([]*twitter.Tweet{{Text: "#go", User: twitter.User{Name: "name"}}})
+			}
+		}()
+		for _, name := range []string{"mono", "solarized", "default", "high-contrast"} {
+			assert.NoError(t, tw.ProcessCommand("theme "+name))
+		}
+		<-done
+	})
+	assert.Len(t, printed, 24)
+	assert.Equal(t, "high-contrast", tw.theme().Name)
+}
+
+func TestLoadConfig_ThemeName(t *testing.T) {
+	withThemesDir(t, nil)
+	viperSave := tsViper
+	defer func() { tsViper = viperSave }()
+
+	tests := []struct {
+		name    string
+		theme   Theme
+		want    Theme
+		mention string
+		hasErr  bool
+	}{
+		{"name only", Theme{Name: "solarized"}, findThemeOrFail(t, "solarized"), "#268bd2", false},
+		{"with colors", Theme{Name: "custom", Colors: map[string]string{colorAuthor: "red"}},
+			Theme{Name: "custom", Colors: map[string]string{colorAuthor: "red"}}, "", false},
+		{"unknown", Theme{Name: "missing"}, DefaultTheme(), "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viperMock := new(mocks.Viper)
+			viperMock.On("ReadInConfig").Return(nil)
+			viperMock.On("UnmarshalKey", "config", mock.AnythingOfType("*app.TweetStreem")).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(*TweetStreem).Theme = test.theme
+				}).
+				Return(nil)
+			tsViper = viperMock
+
+			ts := &TweetStreem{}
+			err := ts.LoadConfig()
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, ts.Theme)
+			assert.Equal(t, test.mention, ts.TemplateOutputConfig.MentionHighlightColor, "the theme colors are applied")
+			viperMock.AssertExpectations(t)
+		})
+	}
+}
+
+func findThemeOrFail(t *testing.T, name string) Theme {
+	th, err := findTheme(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return th
+}
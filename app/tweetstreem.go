@@ -5,11 +5,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -18,25 +24,50 @@ import (
 )
 
 const (
-	DefaultPort                  = 8080
-	DefaultMentionHighlightColor = "blue"
-	DefaultHashtagHighlightColor = "magenta"
+	DefaultPort                      = 8080
+	DefaultMentionHighlightColor     = "blue"
+	DefaultHashtagHighlightColor     = "magenta"
+	DefaultURLHighlightColor         = "cyan"
+	DefaultCashtagHighlightColor     = "green"
+	DefaultMediaHighlightColor       = "yellow"
+	DefaultSelfMentionHighlightColor = "red"
+	DefaultLinkFormat                = twitter.LinkFormatExpanded
+	DefaultTimeFormat                = twitter.TimeFormatRelative
 )
 
 type TweetStreem struct {
 	TwitterConfiguration *twitter.Configuration `json:"twitterConfiguration"`
 	TweetTemplate        string                 `json:"tweetTemplate"`
 	TemplateOutputConfig twitter.OutputConfig   `json:"templateOutputConfig"`
+	HighlightRules       []*HighlightRule       `json:"highlightRules"`
+	Theme                Theme                  `json:"theme"`
+	Preview              PreviewConfig          `json:"preview"`
 	EnableApi            bool                   `json:"enableApi"`
 	EnableClientLinks    bool                   `json:"enableClientLinks"`
 	ApiPort              int                    `json:"apiPort"`
 	ApiHost              string                 `json:"apiHost"`
 	AutoHome             bool                   `json:"autoHome"`
+	HistorySize          int                    `json:"historySize"`
+	EnableArchive        bool                   `json:"enableArchive"`
+	Filters              []*Filter              `json:"filters"`
+	Automation           AutomationConfig       `json:"automation"`
+	EnableLineEditor     bool                   `json:"enableLineEditor"`
 
 	rpcListener    RPCListener
 	tweetTemplate  *template.Template
 	twitter        twitter.Client
 	tweetHistory   *History
+	timeline       *timelineView
+	bookmarks      *Bookmarks
+	noBookmarkAPI  atomic.Bool // set once the bookmark api fails, bookmarks are then only stored locally
+	archive        *Archive
+	filterLock     sync.Mutex   // guards the filters and their counters, tweets are filtered by the poller and by commands
+	automationLock sync.Mutex   // guards the kill switch, dry run and rate caps of the automations
+	templateLock   sync.RWMutex // guards the theme, template output config and parsed template, themes are switched while the poller prints
+	editor         *util.LineEditor
+	lineHistory    *util.LineHistory
+	restoreInput   func() error // restores the terminal when the line editor stops
+	userList       []twitter.User
 	inputCh        chan string
 	printCh        chan string
 	rpcCh          chan string
@@ -47,9 +78,11 @@ type TweetStreem struct {
 }
 
 const DefaultTweetTemplate = `
-{{ .UserName | color "cyan" }} {{ "@" | color "green" }}{{ .ScreenName | color "green" }} {{ .RelativeTweetTime | color "magenta" }}
-id:{{ .Id }} {{ "rt:" | color "cyan" }}{{ .ReTweetCount | color "cyan" }} {{ "♥:" | color "red" }}{{ .FavoriteCount | color "red" }} via {{ .App | color "blue" }}
-{{ .TweetText }}
+{{ with .RetweetedBy }}{{ "↻" | color "retweet" }} {{ .Name | color "author" }} retweeted
+{{ end }}{{ .UserName | color "author" }} {{ "@" | color "screenname" }}{{ .ScreenName | color "screenname" }} {{ .RelativeTweetTime | color "time" }}
+id:{{ .Id }} {{ "rt:" | color "retweet" }}{{ .ReTweetCount | color "retweet" }} {{ "♥:" | color "like" }}{{ .FavoriteCount | color "like" }} via {{ .App | color "app" }}
+{{ .TweetText | wrap 0 }}{{ with .Poll }}
+{{ . }}{{ end }}
 `
 
 func NewTweetStreem(ctx context.Context) *TweetStreem {
@@ -61,25 +94,84 @@ func NewTweetStreem(ctx context.Context) *TweetStreem {
 		ApiPort:              DefaultPort,
 		TwitterConfiguration: &twitter.Configuration{},
 		TemplateOutputConfig: twitter.OutputConfig{
-			MentionHighlightColor: DefaultMentionHighlightColor,
-			HashtagHighlightColor: DefaultHashtagHighlightColor,
-			Highlight:             true,
+			MentionHighlightColor:     DefaultMentionHighlightColor,
+			HashtagHighlightColor:     DefaultHashtagHighlightColor,
+			URLHighlightColor:         DefaultURLHighlightColor,
+			CashtagHighlightColor:     DefaultCashtagHighlightColor,
+			MediaHighlightColor:       DefaultMediaHighlightColor,
+			SelfMentionHighlightColor: DefaultSelfMentionHighlightColor,
+			Highlight:                 true,
+			LinkFormat:                DefaultLinkFormat,
+			TimeFormat:                DefaultTimeFormat,
+			RelativeTimeThreshold:     twitter.DefaultRelativeTimeThreshold.String(),
+			AbsoluteTimeThreshold:     twitter.DefaultAbsoluteTimeThreshold.String(),
+			AbsoluteTimeLayout:        twitter.RelativeTweetTimeOutputLayout,
 		},
-		TweetTemplate: DefaultTweetTemplate,
-		tweetHistory:  NewHistory(),
-		inputCh:       make(chan string),
-		printCh:       make(chan string, 5),
-		rpcCh:         make(chan string, 5),
-		ctx:           twctx,
-		cancel:        cancel,
+		Theme: Theme{Name: DefaultThemeName},
+		Preview: PreviewConfig{
+			Protocol:  string(util.ImageProtocolAuto),
+			MaxWidth:  DefaultPreviewMaxWidth,
+			MaxHeight: DefaultPreviewMaxHeight,
+			MaxBytes:  DefaultPreviewMaxBytes,
+		},
+		TweetTemplate:    DefaultTweetTemplate,
+		HistorySize:      DefaultHistorySize,
+		EnableArchive:    true,
+		Automation:       DefaultAutomationConfig(),
+		EnableLineEditor: true,
+		tweetHistory:     NewHistory(DefaultHistorySize),
+		bookmarks:        NewBookmarks(filepath.Join(configPath, bookmarksFile)),
+		archive:          NewArchive(filepath.Join(configPath, archiveFile)),
+		inputCh:          make(chan string),
+		printCh:          make(chan string, 5),
+		rpcCh:            make(chan string, 5),
+		ctx:              twctx,
+		cancel:           cancel,
 	}
 }
 
-func (t *TweetStreem) getHistoryTweet(id int) (*twitter.Tweet, error) {
-	if tw, ok := t.tweetHistory.Last(id); ok {
-		return tw.(*twitter.Tweet), nil
+// maxHistoryID separates history ids from tweet ids, twitter's tweet ids have been larger for over a decade
+const maxHistoryID = 1_000_000_000
+
+// lookupTweet returns the tweet for a history id, a tweet id or a tweet url, tweets that are not in
+// the history are retrieved from twitter and logged to the history.
+func (t *TweetStreem) lookupTweet(ref string) (*twitter.Tweet, error) {
+	idStr, ok := twitter.ParseStatusURL(ref)
+	if !ok {
+		id, err := strconv.ParseUint(ref, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tweet id %q", ref)
+		}
+		if id < maxHistoryID {
+			if tw, ok := t.tweetHistory.Last(int(id)); ok {
+				return tw, nil
+			}
+			return nil, fmt.Errorf("unknown tweet - id:%d", id)
+		}
+		idStr = ref
+	}
+	if tw, _, ok := t.tweetHistory.Lookup(idStr); ok {
+		return tw, nil
 	}
-	return nil, fmt.Errorf("unknown tweet - id:%d", id)
+	tw, err := t.twitter.ShowStatus(idStr, twitter.NewURLValues())
+	if err != nil {
+		return nil, err
+	}
+	t.tweetHistory.Log(tw)
+	return tw, nil
+}
+
+// firstTweetRef returns the first of the arguments that refers to a tweet, a number or a tweet url.
+func firstTweetRef(args ...string) (string, bool) {
+	for _, a := range args {
+		if _, err := strconv.ParseUint(a, 10, 64); err == nil {
+			return a, true
+		}
+		if _, ok := twitter.ParseStatusURL(a); ok {
+			return a, true
+		}
+	}
+	return "", false
 }
 
 func (t *TweetStreem) RemoteCall() error {
@@ -91,13 +183,11 @@ func (t *TweetStreem) RemoteCall() error {
 	return nil
 }
 
+// parseTemplate parses the tweet template with the theme's helpers, once tweets are printed
+// the caller must hold templateLock.
 func (t *TweetStreem) parseTemplate() error {
-	templateHelpers := map[string]interface{}{
-		"color":  util.Colors.Colorize,
-		"format": formatCreatedAt,
-	}
 	tpl, err := template.New("tweetstreem").
-		Funcs(templateHelpers).
+		Funcs(templateFuncs(t.Theme, &t.TemplateOutputConfig)).
 		Parse(t.TweetTemplate)
 	if err != nil {
 		return err
@@ -126,29 +216,62 @@ func (t *TweetStreem) InitTwitter() error {
 		if err := t.twitter.Authorize(); err != nil {
 			return err
 		}
+		t.TemplateOutputConfig.ScreenName = t.twitter.ScreenName()
+		if t.TemplateOutputConfig.TimeZone == "" {
+			t.setTimeZone(t.twitter.TimeZone())
+		}
 	}
 	return nil
 }
 
+// setTimeZone shows times in the named time zone, falling back to local time if it is not valid.
+func (t *TweetStreem) setTimeZone(name string) {
+	loc, err := twitter.LoadLocation(name)
+	if err != nil {
+		loc = time.Local
+	}
+	t.TemplateOutputConfig.Location = loc
+}
+
 func (t *TweetStreem) WaitForDone() {
 	select {
 	case <-t.ctx.Done():
 	case <-util.Signal():
 		t.cancel()
 	}
+	t.stopLineEditor()
 }
 
 func (t *TweetStreem) pollAndEcho() {
+	// automations only act on tweets posted after the stream started
+	started := timeNow()
 	tweetCh := make(chan []*twitter.Tweet)
 	t.twitter.StartPoller(tweetCh)
 	for tweets := range tweetCh {
-		t.PrintTweets(tweets)
+		unseen := t.unseenTweets(tweets)
+		// automations only act on the tweets that were not muted
+		t.automate(t.printTweets(unseen, true), started)
+	}
+}
+
+// unseenTweets filters out the tweets already in the history, so the stream shows each tweet once.
+func (t *TweetStreem) unseenTweets(tweets []*twitter.Tweet) []*twitter.Tweet {
+	unseen := make([]*twitter.Tweet, 0, len(tweets))
+	for _, tw := range tweets {
+		if _, _, ok := t.tweetHistory.Lookup(tw.IDStr); !ok || tw.IDStr == "" {
+			unseen = append(unseen, tw)
+		}
 	}
+	return unseen
 }
 
 var stdin io.Reader = os.Stdin
 
 func (t *TweetStreem) watchStdin() {
+	if t.editor != nil {
+		t.readLines()
+		return
+	}
 	in := bufio.NewScanner(stdin)
 	for {
 		select {
@@ -173,9 +296,13 @@ func (t *TweetStreem) userConfirmation(msg, abort string, defaultYes bool) bool
 		request += "(N/y):"
 	}
 	t.print(fmt.Sprintln(msg))
-	t.print(request)
+	if t.editor != nil {
+		t.editor.SetPrompt(request)
+	} else {
+		t.print(request)
+	}
 	confirmation := <-t.inputCh
-	confIn, _ := util.SplitCommand(confirmation)
+	confIn := strings.ToLower(strings.TrimSpace(confirmation))
 	if (defaultYes && confIn == "") || confIn == "y" || confIn == "yes" {
 		return true
 	}
@@ -199,47 +326,16 @@ func (t *TweetStreem) ProcessCommand(input string) error {
 }
 
 func (t *TweetStreem) processCommand(isRpc bool, input string) error {
-	command, args := util.SplitCommand(input)
-	switch command {
-	//case "c": // clear screen
-	case "p", "pause": // pause the streem
-		t.pause()
-	case "r", "resume": // unpause the streem
-		t.resume()
-	case "v", "version", "info": // show appInfo
-		t.print(appInfo())
-	case "o", "open":
-		return t.commandOpen(isRpc, args...)
-	case "b", "browse":
-		return t.commandBrowse(isRpc, args...)
-	case "t", "tweet":
-		t.commandTweet(args...)
-	case "reply":
-		t.commandReply(args...)
-	case "cbreply":
-		t.clipBoardReply(args...)
-	case "urt", "unretweet":
-		t.commandUnReTweet(args...)
-	case "rt", "retweet":
-		t.commandRetweet(args...)
-	case "ul", "unlike":
-		t.commandUnLike(args...)
-	case "li", "like":
-		t.commandLike(args...)
-	case "config":
-		t.print(t.config())
-	case "me":
-		return t.userTimeline(t.twitter.ScreenName())
-	case "home":
-		return t.homeTimeline()
-	case "h", "help":
-		t.print(t.help())
-	case "q", "quit", "exit":
-		t.cancel()
-	default:
-		t.println("unknown command:", command)
+	call, err := parseCommandLine(input)
+	if errors.Is(err, errUnknownCommand) {
+		t.println(err) // not an error, so scripts and rpc clients carry on as they always have
+		return nil
 	}
-	return nil
+	if err != nil || call == nil {
+		return err
+	}
+	call.isRpc = isRpc
+	return call.Command.Run(t, call)
 }
 
 func (t *TweetStreem) StartSubsystems() error {
@@ -247,17 +343,21 @@ func (t *TweetStreem) StartSubsystems() error {
 		return err
 	}
 
-	go t.consumeInput()
-	go t.outputPrinter()
-	go t.pollAndEcho()
-	go t.watchStdin()
-
+	util.WatchTerminalWidth(t.ctx)
 	if t.EnableApi {
 		fmt.Println("rpc listener enabled on port:", t.ApiPort)
 		if err := t.InitApi(); err != nil {
 			return err
 		}
 	}
+
+	// the line editor changes the terminal mode, so it is started once nothing else can fail
+	t.startLineEditor()
+	go t.consumeInput()
+	go t.outputPrinter()
+	go t.pollAndEcho()
+	go t.watchStdin()
+
 	if t.AutoHome {
 		_ = t.homeTimeline()
 	}
@@ -267,11 +367,17 @@ func (t *TweetStreem) StartSubsystems() error {
 func (t *TweetStreem) consumeInput() {
 	userPrompt := fmt.Sprintf("[@%s] ", t.twitter.ScreenName())
 	for {
-		t.print(util.Colors.Colorize("red", userPrompt))
+		prompt := t.theme().Colorize(colorPrompt, userPrompt)
+		if t.editor != nil {
+			t.editor.SetPrompt(prompt)
+		} else {
+			t.print(prompt)
+		}
 		select {
 		case <-t.ctx.Done():
 			return
 		case input := <-t.inputCh:
+			t.addLineHistory(input)
 			if err := t.ProcessCommand(input); err != nil {
 				t.print(fmt.Sprintln("Error:", err))
 			}
@@ -306,31 +412,16 @@ var outputWriter = func(s string) { fmt.Print(s) }
 
 func (t *TweetStreem) outputPrinter() {
 	for m := range t.printCh {
-		outputWriter(m)
+		if t.editor != nil {
+			t.editor.Print(m)
+		} else {
+			outputWriter(m)
+		}
 	}
 }
 
 func (t *TweetStreem) help() string {
-	return fmt.Sprintln("Options:\n" +
-		"config - show the current configuration\n" +
-		"p,pause - pause the stream\n" +
-		"r,resume - resume the stream\n" +
-		"v,appInfo - print tweetstreem appInfo\n" +
-		"Select a tweet by id, eg: 'open 2'\n" +
-		" o,open - open the link in the selected tweet (optionally provide 0 based index)\n" +
-		" b,browse - open the selected tweet in a browser\n" +
-		" rt,retweet - retweet the selected tweet\n" +
-		" urt,unretweet - uretweet the selected tweet\n" +
-		" li,like - like the selected tweet\n" +
-		" ul,unlike - unlike the selected tweet\n" +
-		" reply <id> <status> - reply to the tweet id (requires user mention, and confirmation)\n" +
-		" cbreply <id> - reply to tweet id with clipboard contents (requires confirmation)\n" +
-		"t,tweet <status> - create a new tweet and post (requires confirmation)\n" +
-		"me - view your recent tweets\n" +
-		"home - view your default timeline\n" +
-		"h,help - this help menu\n" +
-		"q,quit,exit - exit tweetstreem.")
-
+	return fmt.Sprintln("Options:\n" + commandsHelp())
 }
 
 func (t *TweetStreem) config() string {
@@ -351,39 +442,167 @@ func (t *TweetStreem) pause() {
 	t.twitter.SetPollerPaused(true)
 }
 
+// timelineFetcher retrieves a page of a timeline for the given request values.
+type timelineFetcher func(conf url.Values) ([]*twitter.Tweet, error)
+
+// timelineView is the timeline currently being viewed, it remembers the
+// request that created it so older pages can be retrieved.
+type timelineView struct {
+	name     string
+	fetch    timelineFetcher
+	conf     url.Values
+	oldestID int64
+}
+
+// update tracks the oldest tweet seen in the view.
+func (v *timelineView) update(tweets []*twitter.Tweet) {
+	for _, tw := range tweets {
+		if id := tweetID(tw); id > 0 && (v.oldestID == 0 || id < v.oldestID) {
+			v.oldestID = id
+		}
+	}
+}
+
+// olderConf returns the request values for the page preceding the oldest tweet seen.
+func (v *timelineView) olderConf() (url.Values, bool) {
+	if v.oldestID < 1 {
+		return nil, false
+	}
+	conf := make(url.Values)
+	for k, vals := range v.conf {
+		conf[k] = append([]string(nil), vals...)
+	}
+	// max_id is inclusive, so step back one to avoid repeating the oldest tweet.
+	conf.Set("max_id", strconv.FormatInt(v.oldestID-1, 10))
+	return conf, true
+}
+
+func tweetID(tw *twitter.Tweet) int64 {
+	if tw.ID != 0 {
+		return tw.ID
+	}
+	id, _ := strconv.ParseInt(tw.IDStr, 10, 64)
+	return id
+}
+
 func (t *TweetStreem) homeTimeline() error {
-	tweets, err := t.twitter.HomeTimeline(twitter.NewURLValues())
+	return t.showTimeline("home", t.twitter.HomeTimeline, twitter.NewURLValues())
+}
+
+func (t *TweetStreem) userTimeline(screenName string, cfg url.Values) error {
+	cfg.Set("screen_name", screenName)
+	return t.showTimeline("@"+screenName, t.twitter.UserTimeline, cfg)
+}
+
+// MaxTimelineCount is the largest page size the twitter api will return for a timeline.
+const MaxTimelineCount = 200
+
+func (t *TweetStreem) commandUser(noReplies, noRetweets bool, args ...string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("a screen name or tweet id is required, eg: 'user @someone'")
+	}
+	screenName, err := t.resolveScreenName(args[0])
 	if err != nil {
 		return err
 	}
-	t.tweetHistory.Clear()
+	cfg := twitter.NewURLValues()
+	if noReplies {
+		cfg.Set("exclude_replies", "true")
+	}
+	if noRetweets {
+		cfg.Set("include_rts", "false")
+	}
+	for _, arg := range args[1:] {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > MaxTimelineCount {
+			return fmt.Errorf("invalid count %q, must be between 1 and %d", arg, MaxTimelineCount)
+		}
+		cfg.Set("count", arg)
+	}
+	return t.userTimeline(screenName, cfg)
+}
+
+// resolveScreenName turns a user reference into a screen name, the reference can be
+// '@name', a history id (the author of that tweet) or 'id.N' (the Nth user mentioned in that tweet).
+// a bare number that is not in the history is taken as a screen name, '@' always is one.
+func (t *TweetStreem) resolveScreenName(ref string) (string, error) {
+	if strings.HasPrefix(ref, "@") {
+		if len(ref) < 2 {
+			return "", fmt.Errorf("invalid screen name %q", ref)
+		}
+		return ref[1:], nil
+	}
+	idStr, mentionStr, isMention := strings.Cut(ref, ".")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return ref, nil // not a history reference, so assume a bare screen name
+	}
+	if !isMention && id < maxHistoryID {
+		if _, ok := t.tweetHistory.Last(int(id)); !ok {
+			return ref, nil // not in the history, so a numeric screen name
+		}
+	}
+	tw, err := t.findTweet(idStr)
+	if err != nil {
+		return "", err
+	}
+	if !isMention {
+		return tw.User.ScreenName, nil
+	}
+	idx, err := strconv.Atoi(mentionStr)
+	if err != nil || idx < 0 || idx >= len(tw.Entities.UserMention) {
+		return "", fmt.Errorf("could not find user mention for index: %s", mentionStr)
+	}
+	return tw.Entities.UserMention[idx].ScreenName, nil
+}
+
+// showTimeline replaces the history with the first page of the given timeline.
+func (t *TweetStreem) showTimeline(name string, fetch timelineFetcher, conf url.Values) error {
+	tweets, err := fetch(conf)
+	if err != nil {
+		return err
+	}
+	t.timeline = &timelineView{name: name, fetch: fetch, conf: conf}
+	t.timeline.update(tweets)
 	t.PrintTweets(tweets)
 	return nil
 }
 
-func (t *TweetStreem) userTimeline(screenName string) error {
-	cfg := twitter.NewURLValues()
-	cfg.Set("screen_name", screenName)
-	tweets, err := t.twitter.UserTimeline(cfg)
+// olderTimeline appends the next page of older tweets from the current timeline to the history.
+func (t *TweetStreem) olderTimeline() error {
+	if t.timeline == nil {
+		return fmt.Errorf("no timeline to page through, try 'home' or 'me' first")
+	}
+	conf, ok := t.timeline.olderConf()
+	if !ok {
+		return fmt.Errorf("no older tweets for timeline %s", t.timeline.name)
+	}
+	tweets, err := t.timeline.fetch(conf)
 	if err != nil {
 		return err
 	}
-	t.tweetHistory.Clear()
+	if len(tweets) == 0 {
+		t.println("no older tweets for timeline", t.timeline.name)
+		return nil
+	}
+	t.timeline.update(tweets)
 	t.PrintTweets(tweets)
 	return nil
 }
 
-func (t *TweetStreem) findTweet(id int) (*twitter.Tweet, error) {
-	tw, err := t.getHistoryTweet(id)
+// findTweet returns the tweet to act on for the given history id, tweet id or tweet url,
+// for a retweet this is the original status, as that is what is displayed.
+func (t *TweetStreem) findTweet(ref string) (*twitter.Tweet, error) {
+	tw, err := t.lookupTweet(ref)
 	if err != nil {
 		return nil, err
 	}
-	return tw, nil
+	return tw.Original(), nil
 }
 
 func (t *TweetStreem) commandBrowse(isRpc bool, args ...string) error {
-	if n, ok := util.FirstNumber(args...); ok {
-		if tw, err := t.findTweet(n); err != nil {
+	if ref, ok := firstTweetRef(args...); ok {
+		if tw, err := t.findTweet(ref); err != nil {
 			t.print(fmt.Sprintln(err))
 		} else {
 			return t.browse(isRpc, tw)
@@ -412,18 +631,31 @@ func (t *TweetStreem) browse(isRpc bool, tw *twitter.Tweet) error {
 }
 
 func (t *TweetStreem) commandOpen(isRpc bool, args ...string) error {
-	if n, ok := util.FirstNumber(args...); ok {
-		idx, ok := util.FirstNumber(args[1:]...) // TODO:(smt) range check?
-		if !ok {
-			idx = 0
-		}
-		tw, err := t.findTweet(n)
+	// after the tweet ids, either the index of the link to open, or all to open every link
+	all, idx := false, 0
+	if len(args) > 1 {
+		all = strings.EqualFold(args[1], "all")
+		idx, _ = util.FirstNumber(args[1:]...)
+	}
+	return t.commandBatch(func(ref string) (string, error) {
+		tw, err := t.findTweet(ref)
 		if err != nil {
-			return err
+			return "", err
 		}
-		return t.open(isRpc, tw, idx)
-	}
-	return fmt.Errorf("invalid tweet id")
+		if !all {
+			return "", t.open(isRpc, tw, idx)
+		}
+		links := tw.Links()
+		if len(links) == 0 {
+			return "", fmt.Errorf("tweet has no links")
+		}
+		for i := range links {
+			if err := t.open(isRpc, tw, i); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}, args...)
 }
 
 // returns url that was opened or requestError
@@ -450,16 +682,85 @@ func (t *TweetStreem) open(isRpc bool, tw *twitter.Tweet, linkIdx int) error {
 	return nil
 }
 
-func (t *TweetStreem) commandTweet(args ...string) {
-	message := strings.Join(args, " ")
+// DefaultPollDuration is how long a poll is open when no duration is given.
+const DefaultPollDuration = 24 * time.Hour
+
+// pollRequest is a poll to attach to a new tweet.
+type pollRequest struct {
+	options  []string
+	duration time.Duration
+}
+
+func (t *TweetStreem) commandTweet(c *CommandCall) {
+	message, poll, err := parseTweetArgs(c)
+	if err != nil {
+		t.print(fmt.Sprintln("Error:", err))
+		return
+	}
 	confirmMsg := fmt.Sprintln("tweet:", message)
+	if poll != nil {
+		confirmMsg += fmt.Sprintf("poll: %s (%s)\n", strings.Join(poll.options, " | "), poll.duration)
+	}
 	abortMsg := "tweet aborted\n"
 	if t.userConfirmation(confirmMsg, abortMsg, true) {
-		msg := t.tweet(message)
+		var msg string
+		if poll != nil {
+			msg = t.tweetPoll(message, poll)
+		} else {
+			msg = t.tweet(message)
+		}
 		t.print(msg)
 	}
 }
 
+// parseTweetArgs returns the tweet text and the poll of the tweet command,
+// eg: 'tweet --poll "A|B|C" --duration 1d which one?'
+func parseTweetArgs(c *CommandCall) (string, *pollRequest, error) {
+	var poll *pollRequest
+	if value, ok := c.Flag("poll"); ok {
+		poll = &pollRequest{duration: DefaultPollDuration}
+		for _, o := range strings.Split(value, "|") {
+			poll.options = append(poll.options, strings.TrimSpace(o))
+		}
+	}
+	if duration, ok := c.Flag("duration"); ok {
+		if poll == nil {
+			return "", nil, fmt.Errorf("--duration requires --poll")
+		}
+		d, err := parseDuration(duration)
+		if err != nil {
+			return "", nil, err
+		}
+		poll.duration = d
+	}
+	if poll != nil {
+		if err := twitter.ValidatePoll(poll.options, poll.duration); err != nil {
+			return "", nil, err
+		}
+	}
+	return c.Text(0), poll, nil
+}
+
+// parseDuration parses a go duration, with the addition of days, eg: '1d', '2d12h', '30m'
+func parseDuration(s string) (time.Duration, error) {
+	var days time.Duration
+	if idx := strings.Index(s, "d"); idx >= 0 {
+		n, err := strconv.Atoi(s[:idx])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days, s = time.Duration(n)*24*time.Hour, s[idx+1:]
+		if s == "" {
+			return days, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return days + d, nil
+}
+
 func (t *TweetStreem) tweet(msg string) string {
 	if len(msg) < 1 {
 		return fmt.Sprintln("some text is required to tweet")
@@ -471,35 +772,45 @@ func (t *TweetStreem) tweet(msg string) string {
 	return fmt.Sprintf("tweet success! [%s]\n", tw.IDStr)
 }
 
-func (t *TweetStreem) commandReply(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
-		msg := strings.Join(args[1:], " ")
-		confirmMsg := fmt.Sprintf("reply to %d: %s", n, msg)
+func (t *TweetStreem) tweetPoll(msg string, poll *pollRequest) string {
+	if len(msg) < 1 {
+		return fmt.Sprintln("some text is required to tweet")
+	}
+	tw, err := t.twitter.CreatePoll(msg, poll.options, poll.duration)
+	if err != nil {
+		return fmt.Sprintln("Error:", err)
+	}
+	return fmt.Sprintf("tweet success! [%s]\n", tw.IDStr)
+}
+
+func (t *TweetStreem) commandReply(arg, msg string) {
+	if ref, ok := firstTweetRef(arg); ok {
+		confirmMsg := fmt.Sprintf("reply to %s: %s", ref, msg)
 		abortMsg := "reply aborted"
 		if t.userConfirmation(confirmMsg, abortMsg, true) {
-			m := t.reply(n, msg)
+			m := t.reply(ref, msg)
 			t.print(m)
 		}
 	}
 }
 
 func (t *TweetStreem) clipBoardReply(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
+	if ref, ok := firstTweetRef(args...); ok {
 		if msg, err := util.ClipboardHelper.ReadAll(); err != nil {
 			t.print(fmt.Sprintln("Error:", err))
 		} else {
-			confirmMsg := fmt.Sprintf("reply to %d: %s", n, msg)
+			confirmMsg := fmt.Sprintf("reply to %s: %s", ref, msg)
 			abortMsg := "reply aborted"
 			if t.userConfirmation(confirmMsg, abortMsg, true) {
-				m := t.reply(n, msg)
+				m := t.reply(ref, msg)
 				t.print(m)
 			}
 		}
 	}
 }
 
-func (t *TweetStreem) reply(id int, msg string) string {
-	tweetAtID, err := t.getHistoryTweet(id)
+func (t *TweetStreem) reply(ref string, msg string) string {
+	tweetAtID, err := t.findTweet(ref)
 	if err != nil {
 		return err.Error()
 	}
@@ -516,103 +827,317 @@ func (t *TweetStreem) reply(id int, msg string) string {
 	return fmt.Sprintf("tweet success! [%s]\n", statusTweet.IDStr)
 }
 
-func (t *TweetStreem) commandRetweet(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
-		msg := t.reTweet(n)
-		t.print(msg)
+func (t *TweetStreem) reTweet(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
+	if err != nil {
+		return "", err
 	}
+	if err := t.twitter.ReTweet(tw, twitter.NewURLValues()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tweet by @%s retweeted\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) reTweet(id int) string {
-	tw, err := t.getHistoryTweet(id)
+func (t *TweetStreem) unReTweet(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	if err := t.twitter.ReTweet(tw, twitter.NewURLValues()); err != nil {
-		return fmt.Sprintln("Error:", err)
+	if err := t.twitter.UnReTweet(tw, twitter.NewURLValues()); err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("tweet by @%s retweeted\n", tw.User.ScreenName)
+	return fmt.Sprintf("tweet by @%s unretweeted\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) commandUnReTweet(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
-		m := t.unReTweet(n)
-		t.print(m)
+func (t *TweetStreem) like(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
+	if err != nil {
+		return "", err
+	}
+	if err := t.twitter.Like(tw, twitter.NewURLValues()); err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("tweet by @%s liked\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) unReTweet(id int) string {
-	tw, err := t.getHistoryTweet(id)
+func (t *TweetStreem) unLike(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	if err := t.twitter.UnReTweet(tw, twitter.NewURLValues()); err != nil {
-		return fmt.Sprintln("Error:", err)
+	if err := t.twitter.UnLike(tw, twitter.NewURLValues()); err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("tweet by @%s unretweeted\n", tw.User.ScreenName)
+	return fmt.Sprintf("tweet by @%s unliked\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) commandLike(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
-		msg := t.like(n)
-		t.print(msg)
+func (t *TweetStreem) commandLikes(args ...string) error {
+	cfg := twitter.NewURLValues()
+	name := "likes"
+	if len(args) > 0 && args[0] != "" {
+		screenName, err := t.resolveScreenName(args[0])
+		if err != nil {
+			return err
+		}
+		cfg.Set("screen_name", screenName)
+		name += " @" + screenName
 	}
+	// favorites/list has no cursor, so 'more' pages by tweet id, but likes are ordered by when they were liked,
+	// so earlier likes of tweets newer than the oldest one shown are skipped
+	return t.showTimeline(name, t.twitter.Favorites, cfg)
 }
 
-func (t *TweetStreem) like(id int) string {
-	tw, err := t.getHistoryTweet(id)
+func (t *TweetStreem) bookmark(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	if err := t.twitter.Like(tw, twitter.NewURLValues()); err != nil {
-		return fmt.Sprintln("Error:", err)
+	added, err := t.addBookmark(tw)
+	if err != nil {
+		return "", err
+	}
+	if !added {
+		return fmt.Sprintf("tweet by @%s already bookmarked\n", tw.User.ScreenName), nil
 	}
-	return fmt.Sprintf("tweet by @%s liked\n", tw.User.ScreenName)
+	return fmt.Sprintf("tweet by @%s bookmarked\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) commandUnLike(args ...string) {
-	if n, ok := util.FirstNumber(args...); ok {
-		msg := t.unLike(n)
-		t.print(msg)
+func (t *TweetStreem) unBookmark(ref string) (string, error) {
+	tw, err := t.findTweet(ref)
+	if err != nil {
+		return "", err
 	}
+	removed, err := t.removeBookmark(tw)
+	if err != nil {
+		return "", err
+	}
+	if !removed {
+		return fmt.Sprintf("tweet by @%s is not bookmarked\n", tw.User.ScreenName), nil
+	}
+	return fmt.Sprintf("tweet by @%s unbookmarked\n", tw.User.ScreenName), nil
 }
 
-func (t *TweetStreem) unLike(id int) string {
-	tw, err := t.getHistoryTweet(id)
+func (t *TweetStreem) commandBookmarks(args ...string) error {
+	if len(args) > 0 && strings.ToLower(args[0]) == "export" {
+		if len(args) < 2 || args[1] == "" {
+			return fmt.Errorf("a file is required, eg: 'bookmarks export bookmarks.json'")
+		}
+		return t.exportBookmarks(args[1])
+	}
+	tweets, err := t.listBookmarks()
 	if err != nil {
-		return err.Error()
+		return err
 	}
-	if err := t.twitter.UnLike(tw, twitter.NewURLValues()); err != nil {
-		return fmt.Sprintln("Error:", err)
+	if len(tweets) == 0 {
+		t.println("no bookmarks")
+		return nil
+	}
+	t.timeline = nil
+	t.PrintTweets(tweets)
+	return nil
+}
+
+func (t *TweetStreem) commandFind(args ...string) error {
+	query, err := ParseArchiveQuery(args...)
+	if err != nil {
+		return err
+	}
+	tweets, err := t.archive.Find(query, DefaultFindLimit)
+	if err != nil {
+		return err
+	}
+	if len(tweets) == 0 {
+		t.println("no matches")
+		return nil
+	}
+	t.timeline = nil
+	t.PrintTweets(tweets)
+	return nil
+}
+
+func (t *TweetStreem) exportBookmarks(path string) error {
+	tweets, err := t.listBookmarks()
+	if err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
 	}
-	return fmt.Sprintf("tweet by @%s unliked\n", tw.User.ScreenName)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+	defer f.Close()
+	if err := writeTweetsJSON(f, tweets); err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+	t.println("bookmarks exported to", path)
+	return nil
+}
+
+// userLister retrieves a list of users related to the given tweet.
+type userLister func(tw *twitter.Tweet, conf url.Values) ([]twitter.User, error)
+
+func (t *TweetStreem) commandListUsers(list userLister, args ...string) error {
+	ref, ok := firstTweetRef(args...)
+	if !ok {
+		return fmt.Errorf("invalid tweet id")
+	}
+	tw, err := t.findTweet(ref)
+	if err != nil {
+		return err
+	}
+	users, err := list(tw, twitter.NewURLValues())
+	if err != nil {
+		return err
+	}
+	t.userList = users
+	if len(users) == 0 {
+		t.println("no users found")
+		return nil
+	}
+	out := ""
+	for i, u := range users {
+		out += t.formatUser(i, u)
+	}
+	t.print(out)
+	return nil
+}
+
+func (t *TweetStreem) formatUser(idx int, u twitter.User) string {
+	th := t.theme()
+	verified := ""
+	if u.Verified {
+		verified = th.Colorize(colorVerified, " ✓")
+	}
+	return fmt.Sprintf("[%d] %s %s%s followers:%d\n", idx,
+		th.Colorize(colorAuthor, u.Name),
+		th.Colorize(colorScreenName, "@"+u.ScreenName),
+		verified, u.FollowersCount)
+}
+
+// resolveListUser finds a user by '@name' or by index into the last user list,
+// when only a name is given, user is nil.
+func (t *TweetStreem) resolveListUser(args ...string) (screenName string, user *twitter.User, err error) {
+	if len(args) < 1 || args[0] == "" {
+		return "", nil, fmt.Errorf("a screen name or user index is required")
+	}
+	if strings.HasPrefix(args[0], "@") && len(args[0]) > 1 {
+		return args[0][1:], nil, nil
+	}
+	idx, err := strconv.Atoi(args[0])
+	if err != nil || idx < 0 || idx >= len(t.userList) {
+		return "", nil, fmt.Errorf("unknown user - index:%s", args[0])
+	}
+	return t.userList[idx].ScreenName, &t.userList[idx], nil
+}
+
+func (t *TweetStreem) commandFollow(args ...string) error {
+	screenName, _, err := t.resolveListUser(args...)
+	if err != nil {
+		return err
+	}
+	if _, err := t.twitter.Follow(screenName, twitter.NewURLValues()); err != nil {
+		return err
+	}
+	t.print(fmt.Sprintf("followed @%s\n", screenName))
+	return nil
+}
+
+func (t *TweetStreem) commandWhois(args ...string) error {
+	screenName, user, err := t.resolveListUser(args...)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		if user, err = t.twitter.UserShow(screenName, twitter.NewURLValues()); err != nil {
+			return err
+		}
+	}
+	t.print(t.formatProfile(user))
+	return nil
+}
+
+func (t *TweetStreem) formatProfile(u *twitter.User) string {
+	th := t.theme()
+	out := fmt.Sprintf("%s %s", th.Colorize(colorAuthor, u.Name), th.Colorize(colorScreenName, "@"+u.ScreenName))
+	if u.Verified {
+		out += th.Colorize(colorVerified, " ✓")
+	}
+	if u.Protected {
+		out += " (protected)"
+	}
+	out += "\n"
+	if u.Description != nil && *u.Description != "" {
+		out += fmt.Sprintln(*u.Description)
+	}
+	if u.Location != nil && *u.Location != "" {
+		out += fmt.Sprintln("location:", *u.Location)
+	}
+	if u.URL != nil && *u.URL != "" {
+		out += fmt.Sprintln("url:", *u.URL)
+	}
+	out += fmt.Sprintf("followers:%d following:%d tweets:%d\n", u.FollowersCount, u.FriendsCount, u.StatusesCount)
+	if u.CreatedAt != "" {
+		out += fmt.Sprintln("joined:", formatCreatedAt(u.CreatedAt, "Jan 2006", t.TemplateOutputConfig.TimeLocation()))
+	}
+	return out
+}
+
+// renderTweet executes the tweet template for the tweet with the given history id.
+func (t *TweetStreem) renderTweet(id int, tweet *twitter.Tweet) (string, error) {
+	t.templateLock.RLock()
+	defer t.templateLock.RUnlock()
+	buf := new(bytes.Buffer)
+	err := t.tweetTemplate.Execute(buf, struct {
+		Id int
+		twitter.TweetTemplateOutput
+	}{
+		Id:                  id,
+		TweetTemplateOutput: tweet.TemplateOutput(t.TemplateOutputConfig),
+	})
+	return buf.String(), err
 }
 
 // PrintTweets iterates over the given list of tweets and sends them to the output.
 func (t *TweetStreem) PrintTweets(tweets []*twitter.Tweet) {
+	t.printTweets(tweets, false)
+}
+
+// printTweets prints the tweets, oldest first, each followed by the previews of its media when previews are on.
+// alert rings the bell and notifies for highlighted tweets.
+// the tweets that were shown, those not muted, are returned in the order given. Listings that are not
+// streamed (alert is false) say how many tweets the filters hid, so a muted request is not just empty.
+func (t *TweetStreem) printTweets(tweets []*twitter.Tweet, alert bool) []*twitter.Tweet {
+	shown := make([]*twitter.Tweet, 0, len(tweets))
 	for i := len(tweets) - 1; i >= 0; i-- {
-		tweet := tweets[i]
-		t.tweetHistory.Log(tweet)
-		buf := new(bytes.Buffer)
-		if err := t.tweetTemplate.Execute(buf, struct {
-			Id int
-			twitter.TweetTemplateOutput
-		}{
-			Id:                  t.tweetHistory.LastIdx(),
-			TweetTemplateOutput: tweet.TemplateOutput(t.TemplateOutputConfig),
-		}); err != nil {
+		if !t.muted(tweets[i]) {
+			shown = append(shown, tweets[i])
+		}
+	}
+	previews := t.autoPreviews(shown)
+	for i, tweet := range shown {
+		id := t.tweetHistory.Log(tweet)
+		if out, err := t.renderTweet(id, tweet); err != nil {
 			t.print(fmt.Sprintln("Error:", err))
 		} else {
-			t.print(buf.String())
+			t.print(t.highlight(tweet, out, alert))
+		}
+		if previews != nil {
+			for _, preview := range previews[i] {
+				t.print(preview)
+			}
 		}
 	}
-}
-
-func formatCreatedAt(in, format string) string {
-	createdTime, err := time.Parse(twitter.CreatedAtTimeLayout, in)
-	if err != nil {
-		// debug: fmt.Printf("time format: %s failed to parse: %s", in, format),
-		return in
+	if hidden := len(tweets) - len(shown); hidden > 0 && !alert {
+		t.print(fmt.Sprintf("%s hidden by filters, see 'filter ls'\n", plural("tweet", "tweets", hidden)))
+	}
+	// archived oldest first, in the order they are shown
+	if t.EnableArchive {
+		if err := t.archive.Add(shown...); err != nil {
+			t.print(fmt.Sprintln("Error:", err))
+		}
+	}
+	visible := make([]*twitter.Tweet, len(shown))
+	for i, tw := range shown {
+		visible[len(shown)-1-i] = tw
 	}
-	return createdTime.Format(format)
+	return visible
 }
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -20,6 +23,18 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestMain(m *testing.M) {
+	// tweets shown in tests are archived, keep them out of the home directory
+	dir, err := os.MkdirTemp("", "tweetstreem")
+	if err != nil {
+		panic(err)
+	}
+	configPath = dir
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestNewTweetStreem(t *testing.T) {
 	theCtx, cancel := context.WithCancel(context.TODO())
 	tw := NewTweetStreem(theCtx)
@@ -27,9 +42,19 @@ func TestNewTweetStreem(t *testing.T) {
 	assert.NotNil(t, tw.TwitterConfiguration)
 	assert.Equal(t, DefaultMentionHighlightColor, tw.TemplateOutputConfig.MentionHighlightColor)
 	assert.Equal(t, DefaultHashtagHighlightColor, tw.TemplateOutputConfig.HashtagHighlightColor)
+	assert.Equal(t, DefaultURLHighlightColor, tw.TemplateOutputConfig.URLHighlightColor)
+	assert.Equal(t, DefaultCashtagHighlightColor, tw.TemplateOutputConfig.CashtagHighlightColor)
+	assert.Equal(t, DefaultMediaHighlightColor, tw.TemplateOutputConfig.MediaHighlightColor)
+	assert.Equal(t, DefaultSelfMentionHighlightColor, tw.TemplateOutputConfig.SelfMentionHighlightColor)
 	assert.True(t, tw.TemplateOutputConfig.Highlight)
+	assert.Equal(t, DefaultLinkFormat, tw.TemplateOutputConfig.LinkFormat)
+	assert.Equal(t, twitter.TimeFormatRelative, tw.TemplateOutputConfig.TimeFormat)
+	old := &twitter.Tweet{CreatedAt: time.Now().Add(-48 * time.Hour).Format(twitter.CreatedAtTimeLayout)}
+	assert.Equal(t, old.RelativeTweetTime(), old.FormatTime(tw.TemplateOutputConfig), "times are shown as before unless configured")
 	assert.Equal(t, DefaultTweetTemplate, tw.TweetTemplate)
 	assert.NotNil(t, tw.tweetHistory)
+	assert.True(t, tw.EnableArchive)
+	assert.NotNil(t, tw.archive)
 	cancel()
 	select {
 	case <-tw.ctx.Done():
@@ -72,6 +97,24 @@ func TestTweetStreem_ProcessCommand_Help(t *testing.T) {
 	}
 }
 
+func TestTweetStreem_ProcessCommand_Unknown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unknown", "nope", "unknown command: nope\n"},
+		{"unknown with args", "Nope 1 2", "unknown command: nope\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tw := NewTweetStreem(context.TODO())
+			assert.NoError(t, tw.ProcessCommand(test.input), "an unknown command is printed, not an error")
+			verifyPrint(t, tw, test.want)
+		})
+	}
+}
+
 func TestTweetStreem_ProcessCommand_Pause(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -261,6 +304,63 @@ func TestTweetStreem_ProcessCommand_Tweet(t *testing.T) {
 	}
 }
 
+func TestTweetStreem_ProcessCommand_TweetPoll(t *testing.T) {
+	twitterMock := new(mocks.Client)
+	twitterMock.On("CreatePoll", "which one?", []string{"A", "B c", "D"}, 36*time.Hour).
+		Return(&twitter.Tweet{IDStr: "0000"}, nil)
+
+	tw := NewTweetStreem(context.TODO())
+	tw.twitter = twitterMock
+	sendConfirmation(t, tw, true)
+	err := tw.ProcessCommand(`tweet --poll "A|B c|D" --duration 1d12h which one?`)
+	assert.NoError(t, err)
+	verifyPrint(t, tw, "tweet: which one?\npoll: A | B c | D (36h0m0s)\n\n")
+	verifyPrint(t, tw, "please confirm (Y/n):")
+	verifyPrint(t, tw, "tweet success! [0000]\n")
+	twitterMock.AssertExpectations(t)
+}
+
+func TestParseTweetArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		text    string
+		poll    *pollRequest
+		wantErr bool
+	}{
+		{"no poll", "hello world", "hello world", nil, false},
+		{"text as typed", `--poll A|B it's  "great"`, `it's  "great"`, &pollRequest{[]string{"A", "B"}, DefaultPollDuration}, false},
+		{"flags after text", `use --poll A|B -- here`, `use --poll A|B -- here`, nil, false},
+		{"end of flags", "--poll A|B -- --not a flag", "--not a flag", &pollRequest{[]string{"A", "B"}, DefaultPollDuration}, false},
+		{"poll default duration", "--poll A|B hello", "hello", &pollRequest{[]string{"A", "B"}, DefaultPollDuration}, false},
+		{"quoted poll", `--poll "yes | no way" --duration 30m hi`, "hi", &pollRequest{[]string{"yes", "no way"}, 30 * time.Minute}, false},
+		{"days", "--duration 2d --poll A|B hi", "hi", &pollRequest{[]string{"A", "B"}, 48 * time.Hour}, false},
+		{"duration without poll", "--duration 1d hi", "", nil, true},
+		{"missing poll value", "--poll", "", nil, true},
+		{"unknown flag", "--pol A|B hi", "", nil, true},
+		{"bad duration", "--poll A|B --duration soon hi", "", nil, true},
+		{"bad days", "--poll A|B --duration xd hi", "", nil, true},
+		{"invalid poll", "--poll A hi", "", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var text string
+			var poll *pollRequest
+			call, err := parseCommandLine("tweet " + test.input)
+			if err == nil {
+				text, poll, err = parseTweetArgs(call)
+			}
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.text, text)
+			assert.Equal(t, test.poll, poll)
+		})
+	}
+}
+
 func TestTweetStreem_ProcessCommand_Reply(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -489,6 +589,75 @@ func TestTweetStreem_ProcessCommand_Like(t *testing.T) {
 	}
 }
 
+func TestTweetStreem_ProcessCommand_Retweeted(t *testing.T) {
+	obSave := openBrowser
+	defer func() { openBrowser = obSave }()
+
+	original := &twitter.Tweet{
+		IDStr: "100",
+		User:  twitter.User{ScreenName: "author"},
+		Entities: twitter.Entities{
+			Urls: []twitter.URL{{ExpandedURL: "http://example.com/original"}},
+		},
+	}
+	retweet := &twitter.Tweet{
+		IDStr:           "200",
+		User:            twitter.User{ScreenName: "retweeter"},
+		ReTweetedStatus: original,
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		method string
+		output string
+	}{
+		{"like", "like 1", "Like", "tweet by @author liked\n"},
+		{"unlike", "unlike 1", "UnLike", "tweet by @author unliked\n"},
+		{"retweet", "retweet 1", "ReTweet", "tweet by @author retweeted\n"},
+		{"open", "open 1", "", "opening in browser: http://example.com/original\n"},
+		{"browse", "browse 1", "", fmt.Sprintf("opening in browser: %s\n", original.HTMLLink())},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			openBrowser = func(url string) error { return nil }
+			twitterMock := new(mocks.Client)
+			if test.method != "" {
+				twitterMock.On(test.method, original, mock.AnythingOfType("url.Values")).
+					Return(nil)
+			}
+
+			tw := NewTweetStreem(context.TODO())
+			tw.twitter = twitterMock
+			tw.tweetHistory.Log(retweet)
+			assert.NoError(t, tw.ProcessCommand(test.input))
+			verifyPrint(t, tw, test.output)
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTweetStreem_PrintTweets_Retweet(t *testing.T) {
+	original := &twitter.Tweet{
+		IDStr:         "100",
+		FullText:      "original text",
+		FavoriteCount: 42,
+		User:          twitter.User{Name: "Author", ScreenName: "author"},
+	}
+	retweet := &twitter.Tweet{
+		IDStr:           "200",
+		FullText:        "RT @author: original text",
+		User:            twitter.User{Name: "Retweeter", ScreenName: "retweeter"},
+		ReTweetedStatus: original,
+	}
+	tw := NewTweetStreem(context.TODO())
+	tw.TweetTemplate = "{{ with .RetweetedBy }}{{ .ScreenName }} retweeted {{ end }}{{ .ScreenName }} {{ .FavoriteCount }}:{{ .TweetText }}"
+	assert.NoError(t, tw.parseTemplate())
+	tw.PrintTweets([]*twitter.Tweet{retweet, original})
+	verifyPrint(t, tw, "author 42:original text")
+	verifyPrint(t, tw, "retweeter retweeted author 42:original text")
+}
+
 func TestTweetStreem_ProcessCommand_Config(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -607,6 +776,381 @@ func TestTweetStreem_ProcessCommand_Home(t *testing.T) {
 	}
 }
 
+func TestTweetStreem_ProcessCommand_More(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		error bool
+	}{
+		{"more", "more", false},
+		{"older", "older", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newest := &twitter.Tweet{ID: 300, IDStr: "300", User: twitter.User{ScreenName: "test"}}
+			oldest := &twitter.Tweet{ID: 200, IDStr: "200", User: twitter.User{ScreenName: "test"}}
+			older := &twitter.Tweet{ID: 100, IDStr: "100", User: twitter.User{ScreenName: "test"}}
+
+			twitterMock := new(mocks.Client)
+			twitterMock.On("HomeTimeline",
+				mock.MatchedBy(func(uv url.Values) bool { return uv.Get("max_id") == "" })).
+				Return([]*twitter.Tweet{newest, oldest}, nil).Once()
+			twitterMock.On("HomeTimeline",
+				mock.MatchedBy(func(uv url.Values) bool { return uv.Get("max_id") == "199" })).
+				Return([]*twitter.Tweet{older}, nil).Once()
+
+			tw := NewTweetStreem(context.TODO())
+			tw.TweetTemplate = "{{ .Id }}:{{ .TweetText }}"
+			if err := tw.parseTemplate(); err != nil {
+				assert.NoError(t, err)
+			}
+			tw.twitter = twitterMock
+
+			assert.NoError(t, tw.ProcessCommand("home"))
+			verifyPrint(t, tw, "1:")
+			verifyPrint(t, tw, "2:")
+
+			err := tw.ProcessCommand(test.input)
+			if test.error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			verifyPrint(t, tw, "3:")
+			assert.Equal(t, 3, tw.tweetHistory.LastIdx())
+			found, err := tw.findTweet("3")
+			assert.NoError(t, err)
+			assert.Equal(t, older, found)
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_MoreNoTimeline(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	assert.Error(t, tw.ProcessCommand("more"))
+}
+
+func TestTweetStreem_LookupTweet(t *testing.T) {
+	historyTweet := &twitter.Tweet{IDStr: "1234567890123"}
+	fetchedTweet := &twitter.Tweet{IDStr: "2222222222222"}
+	tests := []struct {
+		name    string
+		ref     string
+		fetch   bool
+		want    *twitter.Tweet
+		wantErr string
+	}{
+		{"history id", "1", false, historyTweet, ""},
+		{"unknown history id", "5", false, nil, "unknown tweet - id:5"},
+		{"tweet id in history", "1234567890123", false, historyTweet, ""},
+		{"tweet url in history", "https://twitter.com/someone/status/1234567890123", false, historyTweet, ""},
+		{"fetched tweet id", "2222222222222", true, fetchedTweet, ""},
+		{"fetched tweet url", "https://x.com/someone/status/2222222222222", true, fetchedTweet, ""},
+		{"invalid", "abc", false, nil, `invalid tweet id "abc"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			twitterMock := new(mocks.Client)
+			if test.fetch {
+				twitterMock.On("ShowStatus", fetchedTweet.IDStr, mock.Anything).Return(fetchedTweet, nil)
+			}
+			tw := NewTweetStreem(context.TODO())
+			tw.twitter = twitterMock
+			tw.tweetHistory.Log(historyTweet)
+
+			found, err := tw.lookupTweet(test.ref)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, found)
+			if test.fetch {
+				_, idx, ok := tw.tweetHistory.Lookup(fetchedTweet.IDStr)
+				assert.True(t, ok, "fetched tweets are added to the history")
+				assert.Equal(t, 2, idx)
+			}
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTweetStreem_LookupTweet_FetchError(t *testing.T) {
+	twitterMock := new(mocks.Client)
+	twitterMock.On("ShowStatus", "2222222222222", mock.Anything).Return(nil, assert.AnError)
+	tw := NewTweetStreem(context.TODO())
+	tw.twitter = twitterMock
+
+	_, err := tw.lookupTweet("2222222222222")
+	assert.Equal(t, assert.AnError, err)
+	assert.Zero(t, tw.tweetHistory.LastIdx())
+	twitterMock.AssertExpectations(t)
+}
+
+func TestFirstTweetRef(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		want   string
+		wantOk bool
+	}{
+		{"none", nil, "", false},
+		{"number", []string{"3"}, "3", true},
+		{"url", []string{"https://twitter.com/a/status/123"}, "https://twitter.com/a/status/123", true},
+		{"skips text", []string{"hello", "12"}, "12", true},
+		{"no refs", []string{"hello", "world"}, "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref, ok := firstTweetRef(test.args...)
+			assert.Equal(t, test.want, ref)
+			assert.Equal(t, test.wantOk, ok)
+		})
+	}
+}
+
+func TestTweetStreem_UnseenTweets(t *testing.T) {
+	tw := NewTweetStreem(context.TODO())
+	seen := &twitter.Tweet{IDStr: "1"}
+	tw.tweetHistory.Log(seen)
+	unseen := &twitter.Tweet{IDStr: "2"}
+	noID := &twitter.Tweet{}
+	assert.Equal(t, []*twitter.Tweet{unseen, noID}, tw.unseenTweets([]*twitter.Tweet{seen, unseen, noID}))
+}
+
+func TestTweetStreem_ProcessCommand_User(t *testing.T) {
+	historyTweet := &twitter.Tweet{
+		IDStr: "123",
+		User:  twitter.User{ScreenName: "author"},
+		Entities: twitter.Entities{
+			UserMention: []twitter.UserMention{{ScreenName: "mentioned"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		input      string
+		screenName string
+		count      string
+		noReplies  bool
+		noRts      bool
+		error      bool
+	}{
+		{"screen name", "user @someone", "someone", "", false, false, false},
+		{"u", "u @someone", "someone", "", false, false, false},
+		{"bare screen name", "user someone", "someone", "", false, false, false},
+		{"count", "user @someone 50", "someone", "50", false, false, false},
+		{"exclude replies and retweets", "user @someone --no-replies --no-rts", "someone", "", true, true, false},
+		{"history author", "user 1", "author", "", false, false, false},
+		{"history mention", "user 1.0", "mentioned", "", false, false, false},
+		{"numeric screen name", "user 1234", "1234", "", false, false, false},
+		{"numeric screen name in the history", "user @1", "1", "", false, false, false},
+		{"unknown history id", "user 2.0", "", "", false, false, true},
+		{"unknown mention", "user 1.1", "", "", false, false, true},
+		{"invalid count", "user @someone 201", "", "", false, false, true},
+		{"missing user", "user", "", "", false, false, true},
+		{"empty screen name", "user @", "", "", false, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			twitterMock := new(mocks.Client)
+			twitterMock.On("UserTimeline",
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("screen_name") == test.screenName &&
+						uv.Get("count") == test.count &&
+						(uv.Get("exclude_replies") == "true") == test.noReplies &&
+						(uv.Get("include_rts") == "false") == test.noRts
+				})).
+				Return([]*twitter.Tweet{}, nil)
+
+			tw := NewTweetStreem(context.TODO())
+			tw.twitter = twitterMock
+			tw.tweetHistory.Log(historyTweet)
+
+			err := tw.ProcessCommand(test.input)
+			if test.error {
+				assert.Error(t, err)
+				twitterMock.AssertNotCalled(t, "UserTimeline", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				twitterMock.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Likes(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		screenName string
+	}{
+		{"own likes", "likes", ""},
+		{"user likes", "likes @someone", "someone"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			twitterMock := new(mocks.Client)
+			twitterMock.On("Favorites",
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("screen_name") == test.screenName
+				})).
+				Return([]*twitter.Tweet{{IDStr: "123", Text: "liked"}}, nil)
+
+			tw := NewTweetStreem(context.TODO())
+			tw.TweetTemplate = "{{ .Id }}:{{ .TweetText }}"
+			if err := tw.parseTemplate(); err != nil {
+				assert.NoError(t, err)
+			}
+			tw.twitter = twitterMock
+
+			assert.NoError(t, tw.ProcessCommand(test.input))
+			verifyPrint(t, tw, "1:liked")
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Bookmark(t *testing.T) {
+	tweet := &twitter.Tweet{
+		IDStr: "123",
+		Text:  "bookmarked",
+		User:  twitter.User{ScreenName: "test"},
+	}
+
+	tw := NewTweetStreem(context.TODO())
+	tw.TweetTemplate = "{{ .Id }}:{{ .TweetText }}"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	tw.bookmarks = NewBookmarks(filepath.Join(t.TempDir(), bookmarksFile))
+	// the api is tried once, then bookmarks are only stored locally
+	twitterMock := new(mocks.Client)
+	twitterMock.On("Bookmarks", mock.Anything).Return(nil, assert.AnError).Once()
+	tw.twitter = twitterMock
+
+	assert.NoError(t, tw.ProcessCommand("bookmarks"))
+	verifyPrint(t, tw, fmt.Sprintln("bookmarks api unavailable, bookmarks are stored locally:", assert.AnError))
+	verifyPrint(t, tw, "no bookmarks\n")
+
+	tw.tweetHistory.Log(tweet)
+	assert.NoError(t, tw.ProcessCommand("bookmark 1"))
+	verifyPrint(t, tw, "tweet by @test bookmarked\n")
+	assert.NoError(t, tw.ProcessCommand("bm 1"))
+	verifyPrint(t, tw, "tweet by @test already bookmarked\n")
+
+	assert.NoError(t, tw.ProcessCommand("bookmarks"))
+	verifyPrint(t, tw, "2:bookmarked") // shown again, so it moves to the next id
+
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	assert.NoError(t, tw.ProcessCommand("bookmarks export "+exportPath))
+	verifyPrint(t, tw, fmt.Sprintln("bookmarks exported to", exportPath))
+	assert.FileExists(t, exportPath)
+	assert.Error(t, tw.ProcessCommand("bookmarks export"))
+
+	assert.NoError(t, tw.ProcessCommand("unbookmark 2"))
+	verifyPrint(t, tw, "tweet by @test unbookmarked\n")
+	assert.NoError(t, tw.ProcessCommand("ubm 2"))
+	verifyPrint(t, tw, "tweet by @test is not bookmarked\n")
+	twitterMock.AssertExpectations(t)
+}
+
+func TestTweetStreem_ProcessCommand_Bookmark_API(t *testing.T) {
+	remote := &twitter.Tweet{IDStr: "1", Text: "bookmarked elsewhere", User: twitter.User{ScreenName: "remote"}}
+	tweet := &twitter.Tweet{IDStr: "2", Text: "bookmarked here", User: twitter.User{ScreenName: "test"}}
+
+	tw := NewTweetStreem(context.TODO())
+	tw.TweetTemplate = "{{ .Id }}:{{ .TweetText }}\n"
+	if err := tw.parseTemplate(); err != nil {
+		assert.NoError(t, err)
+	}
+	tw.bookmarks = NewBookmarks(filepath.Join(t.TempDir(), bookmarksFile))
+	twitterMock := new(mocks.Client)
+	twitterMock.On("Bookmark", tweet).Return(nil)
+	twitterMock.On("RemoveBookmark", remote).Return(nil)
+	twitterMock.On("Bookmarks", mock.Anything).Return([]*twitter.Tweet{tweet, remote}, nil)
+	tw.twitter = twitterMock
+
+	tw.tweetHistory.Log(tweet)
+	assert.NoError(t, tw.ProcessCommand("bookmark 1"))
+	verifyPrint(t, tw, "tweet by @test bookmarked\n")
+	local, err := tw.bookmarks.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*twitter.Tweet{tweet}, local, "a local copy is kept")
+
+	// the api lists the most recently bookmarked first, the tweet is listed once
+	assert.NoError(t, tw.ProcessCommand("bookmarks"))
+	verifyPrint(t, tw, "2:bookmarked elsewhere\n")
+	verifyPrint(t, tw, "3:bookmarked here\n")
+
+	// only bookmarked with the api
+	assert.NoError(t, tw.ProcessCommand("unbookmark 2"))
+	verifyPrint(t, tw, "tweet by @remote unbookmarked\n")
+	twitterMock.AssertExpectations(t)
+}
+
+func TestTweetStreem_ProcessCommand_ListUsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		method string
+	}{
+		{"retweeters", "retweeters 1", "Retweeters"},
+		{"likers", "likers 1", "Likers"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tweet := &twitter.Tweet{IDStr: "123", User: twitter.User{ScreenName: "test"}}
+			users := []twitter.User{
+				{Name: "One", ScreenName: "one", FollowersCount: 10},
+				{Name: "Two", ScreenName: "two", FollowersCount: 20, Verified: true},
+			}
+
+			twitterMock := new(mocks.Client)
+			twitterMock.On(test.method, tweet, mock.AnythingOfType("url.Values")).
+				Return(users, nil)
+			twitterMock.On("Follow", "two", mock.AnythingOfType("url.Values")).
+				Return(&users[1], nil)
+
+			tw := NewTweetStreem(context.TODO())
+			tw.twitter = twitterMock
+			tw.tweetHistory.Log(tweet)
+
+			assert.NoError(t, tw.ProcessCommand(test.input))
+			verifyPrint(t, tw, tw.formatUser(0, users[0])+tw.formatUser(1, users[1]))
+
+			assert.NoError(t, tw.ProcessCommand("whois 0"))
+			verifyPrint(t, tw, tw.formatProfile(&users[0]))
+
+			assert.NoError(t, tw.ProcessCommand("follow 1"))
+			verifyPrint(t, tw, "followed @two\n")
+
+			assert.Error(t, tw.ProcessCommand("follow 2"))
+			assert.Error(t, tw.ProcessCommand(test.name))
+			twitterMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTweetStreem_ProcessCommand_Whois(t *testing.T) {
+	description := "about me"
+	user := &twitter.User{Name: "Some One", ScreenName: "someone", Description: &description, FollowersCount: 3}
+
+	twitterMock := new(mocks.Client)
+	twitterMock.On("UserShow", "someone", mock.AnythingOfType("url.Values")).
+		Return(user, nil)
+
+	tw := NewTweetStreem(context.TODO())
+	tw.twitter = twitterMock
+
+	assert.NoError(t, tw.ProcessCommand("whois @someone"))
+	verifyPrint(t, tw, tw.formatProfile(user))
+	assert.Contains(t, tw.formatProfile(user), "about me\n")
+	assert.Contains(t, tw.formatProfile(user), "followers:3 following:0 tweets:0\n")
+	assert.Error(t, tw.ProcessCommand("whois"))
+	twitterMock.AssertExpectations(t)
+}
+
 func TestTweetStreem_ProcessCommand_Quit(t *testing.T) {
 	tests := []struct {
 		name  string
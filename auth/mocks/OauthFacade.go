@@ -53,6 +53,29 @@ func (_m *OauthFacade) Get(client *http.Client, credentials *oauth.Credentials,
 	return r0, r1
 }
 
+// OaJSONRequest provides a mock function with given fields: method, u, body
+func (_m *OauthFacade) OaJSONRequest(method string, u string, body interface{}) ([]byte, error) {
+	ret := _m.Called(method, u, body)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, string, interface{}) []byte); ok {
+		r0 = rf(method, u, body)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, interface{}) error); ok {
+		r1 = rf(method, u, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // OaRequest provides a mock function with given fields: method, u, conf
 func (_m *OauthFacade) OaRequest(method string, u string, conf url.Values) ([]byte, error) {
 	ret := _m.Called(method, u, conf)
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +19,7 @@ type OauthFacade interface {
 	AuthorizationURL(temporaryCredentials *oauth.Credentials, additionalParams url.Values) string
 	RequestToken(client *http.Client, temporaryCredentials *oauth.Credentials, verifier string) (*oauth.Credentials, url.Values, error)
 	OaRequest(method, u string, conf url.Values) ([]byte, error)
+	OaJSONRequest(method, u string, body interface{}) ([]byte, error)
 	SetToken(token string)
 	SetSecret(secret string)
 	Get(client *http.Client, credentials *oauth.Credentials, urlStr string, form url.Values) (*http.Response, error)
@@ -76,6 +79,8 @@ func (o *DefaultOaFacade) OaRequest(method, u string, conf url.Values) ([]byte,
 		resp, err = o.OauthClient.Post(nil, cred, u, conf)
 	case http.MethodGet:
 		resp, err = o.OauthClient.Get(nil, cred, u, conf)
+	case http.MethodDelete:
+		resp, err = o.OauthClient.Delete(nil, cred, u, conf)
 	}
 	if err != nil {
 		return nil, err
@@ -89,3 +94,34 @@ func (o *DefaultOaFacade) OaRequest(method, u string, conf url.Values) ([]byte,
 	}
 	return nil, ErrUnsupportedMethod
 }
+
+// httpDo sends the request, replaceable for testing
+var httpDo = http.DefaultClient.Do
+
+// OaJSONRequest sends a signed request with the given body encoded as json, as the v2 api expects.
+func (o *DefaultOaFacade) OaJSONRequest(method, u string, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", o.UserAgent)
+	cred := &oauth.Credentials{Token: o.Token, Secret: o.Secret}
+	// json bodies are not part of the oauth signature, so no form values are signed
+	if err := o.OauthClient.SetAuthorizationHeader(req.Header, cred, method, req.URL, nil); err != nil {
+		return nil, err
+	}
+	resp, err := httpDo(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("failed: %d - %s", resp.StatusCode, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
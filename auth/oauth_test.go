@@ -46,6 +46,8 @@ func TestDefaultOaFacade_OaRequest(t *testing.T) {
 		{"post success", http.MethodPost, http.StatusOK, false},
 		{"post requestError", http.MethodPost, http.StatusOK, true},
 		{"post 404", http.MethodPost, http.StatusNotFound, false},
+		{"delete success", http.MethodDelete, http.StatusOK, false},
+		{"delete 404", http.MethodDelete, http.StatusNotFound, false},
 		{"put", http.MethodPut, http.StatusOK, true},
 	}
 	for _, test := range tests {
@@ -69,6 +71,9 @@ func TestDefaultOaFacade_OaRequest(t *testing.T) {
 			case http.MethodPost:
 				mockOauthClient.On("Post", nilHttpClient, mock.AnythingOfType("*oauth.Credentials"), theUrl, mock.AnythingOfType("url.Values")).
 					Return(resp, requestError)
+			case http.MethodDelete:
+				mockOauthClient.On("Delete", nilHttpClient, mock.AnythingOfType("*oauth.Credentials"), theUrl, mock.AnythingOfType("url.Values")).
+					Return(resp, requestError)
 			}
 
 			dfac := NewDefaultOaFacade(OauthConfig{})
@@ -85,3 +90,63 @@ func TestDefaultOaFacade_OaRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultOaFacade_OaJSONRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		signError    bool
+		requestError bool
+	}{
+		{"success", http.StatusOK, false, false},
+		{"created", http.StatusCreated, false, false},
+		{"sign error", http.StatusOK, true, false},
+		{"request error", http.StatusOK, false, true},
+		{"403", http.StatusForbidden, false, false},
+	}
+	for _, test := range tests {
+		theUrl := "https://example.com/asdf123"
+		theBody := "this is a test body"
+
+		t.Run(test.name, func(t *testing.T) {
+			var signError, requestError error
+			if test.signError {
+				signError = assert.AnError
+			}
+			if test.requestError {
+				requestError = assert.AnError
+			}
+			mockOauthClient := new(mocks.OauthClient)
+			mockOauthClient.On("SetAuthorizationHeader",
+				mock.AnythingOfType("http.Header"),
+				mock.AnythingOfType("*oauth.Credentials"),
+				http.MethodPost,
+				mock.AnythingOfType("*url.URL"),
+				url.Values(nil)).
+				Return(signError)
+
+			httpDoSave := httpDo
+			defer func() { httpDo = httpDoSave }()
+			httpDo = func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				sent, err := io.ReadAll(req.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"text":"hello"}`, string(sent))
+				body := io.NopCloser(bytes.NewBuffer([]byte(theBody)))
+				return &http.Response{StatusCode: test.statusCode, Body: body}, requestError
+			}
+
+			dfac := NewDefaultOaFacade(OauthConfig{})
+			dfac.OauthClient = mockOauthClient
+
+			output, err := dfac.OaJSONRequest(http.MethodPost, theUrl, map[string]string{"text": "hello"})
+			if !test.signError && !test.requestError && test.statusCode < http.StatusMultipleChoices {
+				assert.NoError(t, err)
+				assert.Equal(t, []byte(theBody), output)
+			} else {
+				assert.Error(t, err)
+			}
+			mockOauthClient.AssertExpectations(t)
+		})
+	}
+}
@@ -3,12 +3,16 @@ package twitter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Setheck/tweetstreem/auth"
 	"github.com/Setheck/tweetstreem/util"
@@ -19,17 +23,29 @@ var (
 	TokenRequestURI      = "https://api.twitter.com/oauth/access_token"
 	AuthorizeURI         = "https://api.twitter.com/oauth/authorize"
 
-	AccountSettingsURI  = "https://api.twitter.com/1.1/account/settings.json"
-	UserTimelineURI     = "https://api.twitter.com/1.1/statuses/user_timeline.json"
-	HomeTimelineURI     = "https://api.twitter.com/1.1/statuses/home_timeline.json"
-	StatusesUpdateURI   = "https://api.twitter.com/1.1/statuses/update.json"
-	FavoritesCreateURI  = "https://api.twitter.com/1.1/favorites/create.json"
-	FavoritesDestroyURI = "https://api.twitter.com/1.1/favorites/destroy.json"
-	FollowersListURI    = "https://api.twitter.com/1.1/followers/list.json"
-	TrendsPlaceURI      = "https://api.twitter.com/1.1/trends/place.json"
+	AccountSettingsURI   = "https://api.twitter.com/1.1/account/settings.json"
+	UserTimelineURI      = "https://api.twitter.com/1.1/statuses/user_timeline.json"
+	HomeTimelineURI      = "https://api.twitter.com/1.1/statuses/home_timeline.json"
+	StatusesUpdateURI    = "https://api.twitter.com/1.1/statuses/update.json"
+	StatusesShowURI      = "https://api.twitter.com/1.1/statuses/show.json"
+	FavoritesCreateURI   = "https://api.twitter.com/1.1/favorites/create.json"
+	FavoritesDestroyURI  = "https://api.twitter.com/1.1/favorites/destroy.json"
+	FavoritesListURI     = "https://api.twitter.com/1.1/favorites/list.json"
+	FollowersListURI     = "https://api.twitter.com/1.1/followers/list.json"
+	FriendshipsCreateURI = "https://api.twitter.com/1.1/friendships/create.json"
+	UsersShowURI         = "https://api.twitter.com/1.1/users/show.json"
+	TrendsPlaceURI       = "https://api.twitter.com/1.1/trends/place.json"
+	StatusesLookupURI    = "https://api.twitter.com/1.1/statuses/lookup.json"
 
 	StatusesRetweetURITemplate   = "https://api.twitter.com/1.1/statuses/retweet/%s.json"
 	StatusesUnRetweetURITemplate = "https://api.twitter.com/1.1/statuses/unretweet/%s.json"
+	StatusesRetweetsURITemplate  = "https://api.twitter.com/1.1/statuses/retweets/%s.json"
+
+	// the v1.1 api has no way to list who liked a tweet, create polls or bookmark, so the v2 endpoints are used
+	LikingUsersURITemplate    = "https://api.twitter.com/2/tweets/%s/liking_users"
+	TweetsURI                 = "https://api.twitter.com/2/tweets"
+	BookmarksURITemplate      = "https://api.twitter.com/2/users/%s/bookmarks"
+	RemoveBookmarkURITemplate = "https://api.twitter.com/2/users/%s/bookmarks/%s"
 
 	TweetLinkUriTemplate = "https://twitter.com/%s/status/%s"
 
@@ -52,15 +68,26 @@ type Client interface {
 	Configuration() Configuration
 	Authorize() error
 	UpdateStatus(status string, conf url.Values) (*Tweet, error)
+	ShowStatus(id string, conf url.Values) (*Tweet, error)
+	CreatePoll(status string, options []string, duration time.Duration) (*Tweet, error)
 	ReTweet(tw *Tweet, conf url.Values) error
 	UnReTweet(tw *Tweet, conf url.Values) error
 	Like(tw *Tweet, conf url.Values) error
 	UnLike(tw *Tweet, conf url.Values) error
 	HomeTimeline(conf url.Values) ([]*Tweet, error)
 	UserTimeline(conf url.Values) ([]*Tweet, error)
+	Favorites(conf url.Values) ([]*Tweet, error)
+	Retweeters(tw *Tweet, conf url.Values) ([]User, error)
+	Likers(tw *Tweet, conf url.Values) ([]User, error)
+	Bookmark(tw *Tweet) error
+	RemoveBookmark(tw *Tweet) error
+	Bookmarks(conf url.Values) ([]*Tweet, error)
+	Follow(screenName string, conf url.Values) (*User, error)
+	UserShow(screenName string, conf url.Values) (*User, error)
 	SetPollerPaused(b bool)
 	StartPoller(tweetCh chan<- []*Tweet)
 	ScreenName() string
+	TimeZone() string
 	Shutdown()
 }
 
@@ -214,6 +241,93 @@ func (t *DefaultClient) UpdateStatus(status string, conf url.Values) (*Tweet, er
 	return tw, nil
 }
 
+// ShowStatus retrieves a single tweet by id
+func (t *DefaultClient) ShowStatus(id string, conf url.Values) (*Tweet, error) {
+	conf.Set("id", id)
+	data, err := t.oauthFacade.OaRequest(http.MethodGet, StatusesShowURI, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	tw := new(Tweet)
+	if err := json.Unmarshal(data, &tw); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+// Poll limits from the twitter api
+const (
+	MinPollOptions      = 2
+	MaxPollOptions      = 4
+	MaxPollOptionLength = 25
+	MinPollDuration     = 5 * time.Minute
+	MaxPollDuration     = 7 * 24 * time.Hour
+)
+
+// ValidatePoll checks the poll options and duration against the twitter api limits
+func ValidatePoll(options []string, duration time.Duration) error {
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return fmt.Errorf("a poll requires %d to %d options", MinPollOptions, MaxPollOptions)
+	}
+	for _, o := range options {
+		if l := utf8.RuneCountInString(o); l < 1 || l > MaxPollOptionLength {
+			return fmt.Errorf("poll option %q must be 1 to %d characters", o, MaxPollOptionLength)
+		}
+	}
+	if duration < MinPollDuration || duration > MaxPollDuration {
+		return fmt.Errorf("poll duration must be between %s and %s", MinPollDuration, MaxPollDuration)
+	}
+	return nil
+}
+
+// v2TweetRequest - from the twitter v2 api
+type v2TweetRequest struct {
+	Text string `json:"text"`
+	Poll struct {
+		Options         []string `json:"options"`
+		DurationMinutes int      `json:"duration_minutes"`
+	} `json:"poll"`
+}
+
+// v2TweetResponse - from the twitter v2 api
+type v2TweetResponse struct {
+	Data struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	} `json:"data"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// CreatePoll tweets the given status with a poll attached
+func (t *DefaultClient) CreatePoll(status string, options []string, duration time.Duration) (*Tweet, error) {
+	if err := ValidatePoll(options, duration); err != nil {
+		return nil, err
+	}
+	req := v2TweetRequest{Text: status}
+	req.Poll.Options = options
+	req.Poll.DurationMinutes = int(duration / time.Minute)
+	data, err := t.oauthFacade.OaJSONRequest(http.MethodPost, TweetsURI, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	resp := &v2TweetResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+	if resp.Title != "" {
+		return nil, fmt.Errorf("%s - %s", resp.Title, resp.Detail)
+	}
+	id, _ := strconv.ParseInt(resp.Data.ID, 10, 64)
+	return &Tweet{ID: id, IDStr: resp.Data.ID, Text: resp.Data.Text}, nil
+}
+
 // ReTweet marks the given tweet as ReTweeted by the current user
 func (t *DefaultClient) ReTweet(tw *Tweet, conf url.Values) error {
 	data, err := t.oauthFacade.OaRequest(http.MethodPost, fmt.Sprintf(StatusesRetweetURITemplate, tw.IDStr), conf)
@@ -289,6 +403,259 @@ func (t *DefaultClient) ListFollowers(conf url.Values) ([]User, error) {
 	return fl.Users, nil
 }
 
+// Retweeters returns the users who most recently retweeted the given tweet (up to 100)
+func (t *DefaultClient) Retweeters(tw *Tweet, conf url.Values) ([]User, error) {
+	data, err := t.oauthFacade.OaRequest(http.MethodGet, fmt.Sprintf(StatusesRetweetsURITemplate, tw.IDStr), conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	var retweets []*Tweet
+	if err := json.Unmarshal(data, &retweets); err != nil {
+		return nil, err
+	}
+	users := make([]User, 0, len(retweets))
+	for _, rt := range retweets {
+		users = append(users, rt.User)
+	}
+	return users, nil
+}
+
+// v2User - from the twitter v2 api
+type v2User struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Username      string `json:"username"`
+	Description   string `json:"description"`
+	Protected     bool   `json:"protected"`
+	Verified      bool   `json:"verified"`
+	PublicMetrics struct {
+		FollowersCount int `json:"followers_count"`
+		FollowingCount int `json:"following_count"`
+		TweetCount     int `json:"tweet_count"`
+		ListedCount    int `json:"listed_count"`
+	} `json:"public_metrics"`
+}
+
+// User converts the v2 user to the v1.1 user model
+func (u v2User) User() User {
+	id, _ := strconv.ParseInt(u.ID, 10, 64)
+	description := u.Description
+	return User{
+		ID:             id,
+		IDStr:          u.ID,
+		Name:           u.Name,
+		ScreenName:     u.Username,
+		Description:    &description,
+		Protected:      u.Protected,
+		Verified:       u.Verified,
+		FollowersCount: u.PublicMetrics.FollowersCount,
+		FriendsCount:   u.PublicMetrics.FollowingCount,
+		StatusesCount:  u.PublicMetrics.TweetCount,
+		ListedCount:    u.PublicMetrics.ListedCount,
+	}
+}
+
+// v2UserList - from the twitter v2 api
+type v2UserList struct {
+	Data   []v2User `json:"data"`
+	Title  string   `json:"title"`
+	Detail string   `json:"detail"`
+}
+
+// v1Params are the v1.1 api parameters, like those set by NewURLValues, the v2 api rejects unknown parameters
+var v1Params = []string{"tweet_mode", "include_entities", "include_cards", "cards_platform"}
+
+// v2URLValues returns a copy of the values without the v1.1 api parameters
+func v2URLValues(conf url.Values) url.Values {
+	v2 := make(url.Values, len(conf))
+	for k, v := range conf {
+		v2[k] = append([]string(nil), v...)
+	}
+	for _, k := range v1Params {
+		v2.Del(k)
+	}
+	return v2
+}
+
+// Likers returns the users who liked the given tweet
+func (t *DefaultClient) Likers(tw *Tweet, conf url.Values) ([]User, error) {
+	params := v2URLValues(conf)
+	params.Set("user.fields", "description,protected,public_metrics,verified")
+	data, err := t.oauthFacade.OaRequest(http.MethodGet, fmt.Sprintf(LikingUsersURITemplate, tw.IDStr), params)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	ul := &v2UserList{}
+	if err := json.Unmarshal(data, ul); err != nil {
+		return nil, err
+	}
+	if ul.Title != "" {
+		return nil, fmt.Errorf("%s - %s", ul.Title, ul.Detail)
+	}
+	users := make([]User, 0, len(ul.Data))
+	for _, u := range ul.Data {
+		users = append(users, u.User())
+	}
+	return users, nil
+}
+
+// ErrNoUserID is returned when the user id can't be taken from the user token
+var ErrNoUserID = errors.New("no user id in the user token")
+
+// userID returns the current user's id, the oauth1 user token is prefixed with it
+func (t *DefaultClient) userID() (string, error) {
+	id, _, found := strings.Cut(t.configuration.UserToken, "-")
+	if !found || id == "" {
+		return "", ErrNoUserID
+	}
+	return id, nil
+}
+
+// v2BookmarkRequest - from the twitter v2 api
+type v2BookmarkRequest struct {
+	TweetID string `json:"tweet_id"`
+}
+
+// v2BookmarkResponse - from the twitter v2 api
+type v2BookmarkResponse struct {
+	Data struct {
+		Bookmarked bool `json:"bookmarked"`
+	} `json:"data"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// v2TweetList - from the twitter v2 api
+type v2TweetList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// Bookmark bookmarks the given tweet for the current user
+func (t *DefaultClient) Bookmark(tw *Tweet) error {
+	id, err := t.userID()
+	if err != nil {
+		return err
+	}
+	data, err := t.oauthFacade.OaJSONRequest(http.MethodPost, fmt.Sprintf(BookmarksURITemplate, id), v2BookmarkRequest{TweetID: tw.IDStr})
+	if err != nil {
+		return err
+	}
+	return t.bookmarkResponse(data)
+}
+
+// RemoveBookmark removes the given tweet from the current user's bookmarks
+func (t *DefaultClient) RemoveBookmark(tw *Tweet) error {
+	id, err := t.userID()
+	if err != nil {
+		return err
+	}
+	data, err := t.oauthFacade.OaRequest(http.MethodDelete, fmt.Sprintf(RemoveBookmarkURITemplate, id, tw.IDStr), url.Values{})
+	if err != nil {
+		return err
+	}
+	return t.bookmarkResponse(data)
+}
+
+func (t *DefaultClient) bookmarkResponse(data []byte) error {
+	if err := t.unmarshalError(data); err != nil {
+		return err
+	}
+	resp := &v2BookmarkResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return err
+	}
+	if resp.Title != "" {
+		return fmt.Errorf("%s - %s", resp.Title, resp.Detail)
+	}
+	return nil
+}
+
+// Bookmarks returns the current user's most recent bookmarks (up to 100), most recently bookmarked first,
+// the v2 api only returns the tweet ids, so the tweets are looked up with the v1.1 api
+func (t *DefaultClient) Bookmarks(conf url.Values) ([]*Tweet, error) {
+	id, err := t.userID()
+	if err != nil {
+		return nil, err
+	}
+	params := v2URLValues(conf)
+	params.Set("max_results", "100")
+	data, err := t.oauthFacade.OaRequest(http.MethodGet, fmt.Sprintf(BookmarksURITemplate, id), params)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	tl := &v2TweetList{}
+	if err := json.Unmarshal(data, tl); err != nil {
+		return nil, err
+	}
+	if tl.Title != "" {
+		return nil, fmt.Errorf("%s - %s", tl.Title, tl.Detail)
+	}
+	if len(tl.Data) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(tl.Data))
+	for _, d := range tl.Data {
+		ids = append(ids, d.ID)
+	}
+	conf.Set("id", strings.Join(ids, ","))
+	found, err := t.getTimeline(StatusesLookupURI, conf)
+	if err != nil {
+		return nil, err
+	}
+	// the lookup doesn't keep the order of the ids, deleted tweets are left out
+	byID := make(map[string]*Tweet, len(found))
+	for _, tw := range found {
+		byID[tw.IDStr] = tw
+	}
+	tweets := make([]*Tweet, 0, len(found))
+	for _, id := range ids {
+		if tw, ok := byID[id]; ok {
+			tweets = append(tweets, tw)
+		}
+	}
+	return tweets, nil
+}
+
+// Follow makes the current user follow the user with the given screen name
+func (t *DefaultClient) Follow(screenName string, conf url.Values) (*User, error) {
+	conf.Set("screen_name", screenName)
+	return t.userRequest(http.MethodPost, FriendshipsCreateURI, conf)
+}
+
+// UserShow returns the user with the given screen name
+func (t *DefaultClient) UserShow(screenName string, conf url.Values) (*User, error) {
+	conf.Set("screen_name", screenName)
+	return t.userRequest(http.MethodGet, UsersShowURI, conf)
+}
+
+func (t *DefaultClient) userRequest(method, uri string, conf url.Values) (*User, error) {
+	data, err := t.oauthFacade.OaRequest(method, uri, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.unmarshalError(data); err != nil {
+		return nil, err
+	}
+	u := new(User)
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
 func (t *DefaultClient) unmarshalError(data []byte) error {
 	var errList TwErrors
 	_ = json.Unmarshal(data, &errList) // We dont' really care if this fails
@@ -306,6 +673,14 @@ func (t *DefaultClient) ScreenName() string {
 	return t.accountSettings.ScreenName
 }
 
+// TimeZone returns the current user's time zone name eg: 'Europe/London', from their account settings
+func (t *DefaultClient) TimeZone() string {
+	if t.accountSettings == nil {
+		return ""
+	}
+	return t.accountSettings.TimeZone.TzinfoName
+}
+
 func (t *DefaultClient) updateAccountSettings() error {
 	raw, err := t.oauthFacade.OaRequest(http.MethodGet, AccountSettingsURI, url.Values{})
 	if err != nil {
@@ -320,9 +695,21 @@ func (t *DefaultClient) updateAccountSettings() error {
 	return json.Unmarshal(raw, &t.accountSettings)
 }
 
-// HomeTimeline retrieve the current user's home timeline
+// HomeTimeline retrieve the current user's home timeline, the newest tweet is where the poller continues from
 func (t *DefaultClient) HomeTimeline(conf url.Values) ([]*Tweet, error) {
-	return t.getTimeline(HomeTimelineURI, conf)
+	timeLine, err := t.getTimeline(HomeTimelineURI, conf)
+	if err != nil {
+		return nil, err
+	}
+	if len(timeLine) > 0 {
+		t.lock.Lock()
+		// only move forward, paging back through older tweets must not rewind the poller
+		if t.lastTweet == nil || timeLine[0].ID >= t.lastTweet.ID {
+			t.lastTweet = timeLine[0]
+		}
+		t.lock.Unlock()
+	}
+	return timeLine, nil
 }
 
 // UserTimeline retrieve a user timeline, specified by "screen_name" config value
@@ -330,7 +717,16 @@ func (t *DefaultClient) UserTimeline(conf url.Values) ([]*Tweet, error) {
 	return t.getTimeline(UserTimelineURI, conf)
 }
 
+// Favorites retrieve the tweets liked by a user, specified by "screen_name" config value
+// or the current user if not set, liked tweets can be newer than the home timeline so the poller is not moved
+func (t *DefaultClient) Favorites(conf url.Values) ([]*Tweet, error) {
+	return t.getTimeline(FavoritesListURI, conf)
+}
+
 func (t *DefaultClient) getTimeline(timelineURI string, conf url.Values) ([]*Tweet, error) {
+	// cards are required for poll results
+	conf.Set("include_cards", "1")
+	conf.Set("cards_platform", "Web-12")
 	rawTweets, err := t.oauthFacade.OaRequest(http.MethodGet, timelineURI, conf)
 	if err != nil {
 		return nil, err
@@ -343,11 +739,6 @@ func (t *DefaultClient) getTimeline(timelineURI string, conf url.Values) ([]*Twe
 	if err := json.Unmarshal(rawTweets, &timeLine); err != nil {
 		return nil, err
 	}
-	if len(timeLine) > 0 {
-		t.lock.Lock()
-		t.lastTweet = timeLine[0]
-		t.lock.Unlock()
-	}
 	return timeLine, nil
 }
 
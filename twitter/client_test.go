@@ -26,6 +26,14 @@ func TestDefaultClient_ScreenName(t *testing.T) {
 	assert.Equal(t, screenName, twitter.ScreenName())
 }
 
+func TestDefaultClient_TimeZone(t *testing.T) {
+	twitter := &DefaultClient{}
+	assert.Equal(t, "", twitter.TimeZone())
+
+	twitter.accountSettings = &AccountSettings{TimeZone: TimeZone{Name: "London", TzinfoName: "Europe/London"}}
+	assert.Equal(t, "Europe/London", twitter.TimeZone())
+}
+
 func TestDefaultClient_Configuration(t *testing.T) {
 	twitter := &DefaultClient{}
 	assert.Equal(t, Configuration{}, twitter.Configuration())
@@ -263,6 +271,119 @@ func TestDefaultClient_UpdateStatus(t *testing.T) {
 	}
 }
 
+func TestDefaultClient_ShowStatus(t *testing.T) {
+	id := "1234"
+	resultTweet := &Tweet{IDStr: id, Text: "testing"}
+
+	tests := []struct {
+		name        string
+		tweetData   []byte
+		tweetError  error
+		expectError bool
+	}{
+		{"success", createTwitterResponseData(t, resultTweet), nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				StatusesShowURI,
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("id") == id
+				}),
+			).Return(test.tweetData, test.tweetError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			tweet, err := twitter.ShowStatus(id, url.Values{})
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, resultTweet, tweet)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_CreatePoll(t *testing.T) {
+	status := "which one?"
+	options := []string{"A", "B"}
+
+	tests := []struct {
+		name        string
+		options     []string
+		duration    time.Duration
+		tweetData   []byte
+		tweetError  error
+		expectError bool
+	}{
+		{"success", options, time.Hour, []byte(`{"data":{"id":"123","text":"which one?"}}`), nil, false},
+		{"api error", options, time.Hour, createTwitterErrorData(t), nil, true},
+		{"v2 api error", options, time.Hour, []byte(`{"title":"Forbidden","detail":"nope"}`), nil, true},
+		{"marshal error", options, time.Hour, []byte("garbage"), nil, true},
+		{"request error", options, time.Hour, nil, assert.AnError, true},
+		{"too few options", []string{"A"}, time.Hour, nil, nil, true},
+		{"too short", options, time.Minute, nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaJSONRequest",
+				http.MethodPost,
+				TweetsURI,
+				mock.MatchedBy(func(req v2TweetRequest) bool {
+					return req.Text == status &&
+						assert.Equal(t, test.options, req.Poll.Options) &&
+						req.Poll.DurationMinutes == int(test.duration/time.Minute)
+				}),
+			).Return(test.tweetData, test.tweetError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			tweet, err := twitter.CreatePoll(status, test.options, test.duration)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &Tweet{ID: 123, IDStr: "123", Text: status}, tweet)
+			}
+		})
+	}
+}
+
+func TestValidatePoll(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []string
+		duration time.Duration
+		valid    bool
+	}{
+		{"valid", []string{"A", "B"}, time.Hour, true},
+		{"four options", []string{"A", "B", "C", "D"}, MaxPollDuration, true},
+		{"one option", []string{"A"}, time.Hour, false},
+		{"five options", []string{"A", "B", "C", "D", "E"}, time.Hour, false},
+		{"empty option", []string{"A", ""}, time.Hour, false},
+		{"long option", []string{"A", "this option is way too long!"}, time.Hour, false},
+		{"short duration", []string{"A", "B"}, time.Minute, false},
+		{"long duration", []string{"A", "B"}, MaxPollDuration + time.Minute, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePoll(test.options, test.duration)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
 func TestDefaultClient_ReTweet(t *testing.T) {
 	tweet := &Tweet{IDStr: "123"}
 
@@ -447,6 +568,316 @@ func TestDefaultClient_ListFollowers(t *testing.T) {
 	}
 }
 
+func TestDefaultClient_Retweeters(t *testing.T) {
+	tweet := &Tweet{IDStr: "123"}
+	expectedUsers := []User{{ID: 1, IDStr: "1", ScreenName: "one"}, {ID: 2, IDStr: "2", ScreenName: "two"}}
+	retweets := []*Tweet{{User: expectedUsers[0]}, {User: expectedUsers[1]}}
+
+	tests := []struct {
+		name        string
+		tweetData   []byte
+		tweetError  error
+		expectError bool
+	}{
+		{"success", createTwitterResponseData(t, retweets), nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				fmt.Sprintf(StatusesRetweetsURITemplate, tweet.IDStr),
+				mock.AnythingOfType("url.Values"),
+			).Return(test.tweetData, test.tweetError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			users, err := twitter.Retweeters(tweet, url.Values{})
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expectedUsers, users)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_Likers(t *testing.T) {
+	tweet := &Tweet{IDStr: "123"}
+	likersResponse := []byte(`{"data":[{"id":"12","name":"Some One","username":"someone","verified":true,
+		"description":"hello","public_metrics":{"followers_count":1200,"following_count":3,"tweet_count":40,"listed_count":5}}]}`)
+	description := "hello"
+	expectedUsers := []User{{
+		ID:             12,
+		IDStr:          "12",
+		Name:           "Some One",
+		ScreenName:     "someone",
+		Description:    &description,
+		Verified:       true,
+		FollowersCount: 1200,
+		FriendsCount:   3,
+		StatusesCount:  40,
+		ListedCount:    5,
+	}}
+
+	tests := []struct {
+		name        string
+		tweetData   []byte
+		tweetError  error
+		expectError bool
+	}{
+		{"success", likersResponse, nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"v2 api error", []byte(`{"title":"Unauthorized","detail":"Unauthorized"}`), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				fmt.Sprintf(LikingUsersURITemplate, tweet.IDStr),
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("user.fields") != "" && !uv.Has("tweet_mode")
+				}),
+			).Return(test.tweetData, test.tweetError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			users, err := twitter.Likers(tweet, NewURLValues())
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expectedUsers, users)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_Bookmark(t *testing.T) {
+	tweet := &Tweet{IDStr: "123"}
+	tests := []struct {
+		name        string
+		userToken   string
+		data        []byte
+		requestErr  error
+		expectError bool
+	}{
+		{"success", "42-token", []byte(`{"data":{"bookmarked":true}}`), nil, false},
+		{"no user id", "token", nil, nil, true},
+		{"api error", "42-token", createTwitterErrorData(t), nil, true},
+		{"v2 api error", "42-token", []byte(`{"title":"Forbidden","detail":"nope"}`), nil, true},
+		{"marshal error", "42-token", []byte("garbage"), nil, true},
+		{"request error", "42-token", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaJSONRequest",
+				http.MethodPost,
+				fmt.Sprintf(BookmarksURITemplate, "42"),
+				v2BookmarkRequest{TweetID: tweet.IDStr},
+			).Return(test.data, test.requestErr)
+
+			twitter := &DefaultClient{configuration: &Configuration{UserToken: test.userToken}}
+			twitter.oauthFacade = mockOauth
+			err := twitter.Bookmark(tweet)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_RemoveBookmark(t *testing.T) {
+	tweet := &Tweet{IDStr: "123"}
+	tests := []struct {
+		name        string
+		userToken   string
+		data        []byte
+		requestErr  error
+		expectError bool
+	}{
+		{"success", "42-token", []byte(`{"data":{"bookmarked":false}}`), nil, false},
+		{"no user id", "-token", nil, nil, true},
+		{"v2 api error", "42-token", []byte(`{"title":"Forbidden","detail":"nope"}`), nil, true},
+		{"request error", "42-token", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodDelete,
+				fmt.Sprintf(RemoveBookmarkURITemplate, "42", tweet.IDStr),
+				mock.AnythingOfType("url.Values"),
+			).Return(test.data, test.requestErr)
+
+			twitter := &DefaultClient{configuration: &Configuration{UserToken: test.userToken}}
+			twitter.oauthFacade = mockOauth
+			err := twitter.RemoveBookmark(tweet)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_Bookmarks(t *testing.T) {
+	idsResponse := []byte(`{"data":[{"id":"3","text":"three"},{"id":"1","text":"one"},{"id":"2","text":"gone"}]}`)
+	// the lookup returns the tweets in any order and leaves out deleted ones
+	lookupResponse := []byte(`[{"id":1,"id_str":"1"},{"id":3,"id_str":"3"}]`)
+
+	tests := []struct {
+		name        string
+		userToken   string
+		idsData     []byte
+		idsErr      error
+		lookupData  []byte
+		lookupErr   error
+		expectIDs   []string
+		expectError bool
+	}{
+		{"success", "42-token", idsResponse, nil, lookupResponse, nil, []string{"3", "1"}, false},
+		{"no bookmarks", "42-token", []byte(`{"meta":{"result_count":0}}`), nil, nil, nil, nil, false},
+		{"no user id", "token", nil, nil, nil, nil, nil, true},
+		{"v2 api error", "42-token", []byte(`{"title":"Forbidden","detail":"nope"}`), nil, nil, nil, nil, true},
+		{"marshal error", "42-token", []byte("garbage"), nil, nil, nil, nil, true},
+		{"request error", "42-token", nil, assert.AnError, nil, nil, nil, true},
+		{"lookup error", "42-token", idsResponse, nil, nil, assert.AnError, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				fmt.Sprintf(BookmarksURITemplate, "42"),
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("max_results") == "100" && !uv.Has("tweet_mode")
+				}),
+			).Return(test.idsData, test.idsErr)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				StatusesLookupURI,
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("id") == "3,1,2" && uv.Get("tweet_mode") == "extended"
+				}),
+			).Return(test.lookupData, test.lookupErr)
+
+			twitter := &DefaultClient{configuration: &Configuration{UserToken: test.userToken}}
+			twitter.oauthFacade = mockOauth
+			tweets, err := twitter.Bookmarks(NewURLValues())
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var ids []string
+			for _, tw := range tweets {
+				ids = append(ids, tw.IDStr)
+			}
+			assert.Equal(t, test.expectIDs, ids)
+		})
+	}
+}
+
+func TestV2URLValues(t *testing.T) {
+	conf := NewURLValues()
+	conf.Set("include_entities", "true")
+	conf.Set("include_cards", "1")
+	conf.Set("cards_platform", "Web-12")
+	conf.Set("max_results", "10")
+
+	v2 := v2URLValues(conf)
+	assert.Equal(t, "max_results=10", v2.Encode(), "the v1.1 parameters are removed")
+	assert.Equal(t, "extended", conf.Get("tweet_mode"), "the values given are not changed")
+}
+
+func TestDefaultClient_Follow(t *testing.T) {
+	expectedUser := &User{ID: 1, IDStr: "1", ScreenName: "someone"}
+
+	tests := []struct {
+		name        string
+		userData    []byte
+		userError   error
+		expectError bool
+	}{
+		{"success", createTwitterResponseData(t, expectedUser), nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodPost,
+				FriendshipsCreateURI,
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("screen_name") == "someone"
+				}),
+			).Return(test.userData, test.userError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			user, err := twitter.Follow("someone", url.Values{})
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expectedUser, user)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_UserShow(t *testing.T) {
+	expectedUser := &User{ID: 1, IDStr: "1", ScreenName: "someone"}
+
+	tests := []struct {
+		name        string
+		userData    []byte
+		userError   error
+		expectError bool
+	}{
+		{"success", createTwitterResponseData(t, expectedUser), nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				UsersShowURI,
+				mock.MatchedBy(func(uv url.Values) bool {
+					return uv.Get("screen_name") == "someone"
+				}),
+			).Return(test.userData, test.userError)
+
+			twitter := &DefaultClient{}
+			twitter.oauthFacade = mockOauth
+			user, err := twitter.UserShow("someone", url.Values{})
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expectedUser, user)
+			}
+		})
+	}
+}
+
 func TestDefaultClient_UserTimeline(t *testing.T) {
 	expectedTweets := []*Tweet{
 		{ID: 123, IDStr: "123"},
@@ -484,7 +915,7 @@ func TestDefaultClient_UserTimeline(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, expectedTweets, tweets)
-				assert.Equal(t, twitter.lastTweet, tweets[0])
+				assert.Nil(t, twitter.lastTweet, "only the home timeline moves the poller")
 			}
 		})
 	}
@@ -533,6 +964,73 @@ func TestDefaultClient_HomeTimeline(t *testing.T) {
 	}
 }
 
+func TestDefaultClient_Favorites(t *testing.T) {
+	expectedTweets := []*Tweet{
+		{ID: 123, IDStr: "123"},
+		{ID: 1243, IDStr: "1243"},
+	}
+
+	tests := []struct {
+		name        string
+		tweetData   []byte
+		tweetError  error
+		expectError bool
+	}{
+		{"success", createTwitterResponseData(t, expectedTweets), nil, false},
+		{"api error", createTwitterErrorData(t), nil, true},
+		{"marshal error", []byte("garbage"), nil, true},
+		{"request error", nil, assert.AnError, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			mockOauth := new(mocks.OauthFacade)
+			mockOauth.On("OaRequest",
+				http.MethodGet,
+				FavoritesListURI,
+				mock.AnythingOfType("url.Values"),
+			).Return(test.tweetData, test.tweetError)
+
+			lastTweet := &Tweet{ID: 100, IDStr: "100"}
+			twitter := &DefaultClient{lastTweet: lastTweet}
+			twitter.oauthFacade = mockOauth
+			tweets, err := twitter.Favorites(url.Values{})
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expectedTweets, tweets)
+			}
+			assert.Equal(t, lastTweet, twitter.lastTweet, "liked tweets do not move the poller")
+		})
+	}
+}
+
+func TestDefaultClient_TimelineKeepsNewestLastTweet(t *testing.T) {
+	newer := &Tweet{ID: 200, IDStr: "200"}
+	older := &Tweet{ID: 100, IDStr: "100"}
+
+	mockOauth := new(mocks.OauthFacade)
+	mockOauth.On("OaRequest",
+		http.MethodGet,
+		HomeTimelineURI,
+		mock.AnythingOfType("url.Values"),
+	).Return(createTwitterResponseData(t, []*Tweet{newer}), nil).Once()
+	mockOauth.On("OaRequest",
+		http.MethodGet,
+		HomeTimelineURI,
+		mock.AnythingOfType("url.Values"),
+	).Return(createTwitterResponseData(t, []*Tweet{older}), nil).Once()
+
+	twitter := &DefaultClient{}
+	twitter.oauthFacade = mockOauth
+	_, err := twitter.HomeTimeline(url.Values{})
+	assert.NoError(t, err)
+	_, err = twitter.HomeTimeline(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, "200", twitter.lastTweet.IDStr)
+}
+
 // Test helper to create a json blob simulating an error coming from the twitter api
 func createTwitterErrorData(t *testing.T) []byte {
 	t.Helper()
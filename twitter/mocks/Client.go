@@ -6,6 +6,8 @@ import (
 	twitter "github.com/Setheck/tweetstreem/twitter"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	url "net/url"
 )
 
@@ -28,6 +30,43 @@ func (_m *Client) Authorize() error {
 	return r0
 }
 
+// Bookmark provides a mock function with given fields: tw
+func (_m *Client) Bookmark(tw *twitter.Tweet) error {
+	ret := _m.Called(tw)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*twitter.Tweet) error); ok {
+		r0 = rf(tw)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Bookmarks provides a mock function with given fields: conf
+func (_m *Client) Bookmarks(conf url.Values) ([]*twitter.Tweet, error) {
+	ret := _m.Called(conf)
+
+	var r0 []*twitter.Tweet
+	if rf, ok := ret.Get(0).(func(url.Values) []*twitter.Tweet); ok {
+		r0 = rf(conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*twitter.Tweet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(url.Values) error); ok {
+		r1 = rf(conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Configuration provides a mock function with given fields:
 func (_m *Client) Configuration() twitter.Configuration {
 	ret := _m.Called()
@@ -42,6 +81,75 @@ func (_m *Client) Configuration() twitter.Configuration {
 	return r0
 }
 
+// CreatePoll provides a mock function with given fields: status, options, duration
+func (_m *Client) CreatePoll(status string, options []string, duration time.Duration) (*twitter.Tweet, error) {
+	ret := _m.Called(status, options, duration)
+
+	var r0 *twitter.Tweet
+	if rf, ok := ret.Get(0).(func(string, []string, time.Duration) *twitter.Tweet); ok {
+		r0 = rf(status, options, duration)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*twitter.Tweet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []string, time.Duration) error); ok {
+		r1 = rf(status, options, duration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Favorites provides a mock function with given fields: conf
+func (_m *Client) Favorites(conf url.Values) ([]*twitter.Tweet, error) {
+	ret := _m.Called(conf)
+
+	var r0 []*twitter.Tweet
+	if rf, ok := ret.Get(0).(func(url.Values) []*twitter.Tweet); ok {
+		r0 = rf(conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*twitter.Tweet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(url.Values) error); ok {
+		r1 = rf(conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Follow provides a mock function with given fields: screenName, conf
+func (_m *Client) Follow(screenName string, conf url.Values) (*twitter.User, error) {
+	ret := _m.Called(screenName, conf)
+
+	var r0 *twitter.User
+	if rf, ok := ret.Get(0).(func(string, url.Values) *twitter.User); ok {
+		r0 = rf(screenName, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*twitter.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, url.Values) error); ok {
+		r1 = rf(screenName, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HomeTimeline provides a mock function with given fields: conf
 func (_m *Client) HomeTimeline(conf url.Values) ([]*twitter.Tweet, error) {
 	ret := _m.Called(conf)
@@ -65,6 +173,29 @@ func (_m *Client) HomeTimeline(conf url.Values) ([]*twitter.Tweet, error) {
 	return r0, r1
 }
 
+// Likers provides a mock function with given fields: tw, conf
+func (_m *Client) Likers(tw *twitter.Tweet, conf url.Values) ([]twitter.User, error) {
+	ret := _m.Called(tw, conf)
+
+	var r0 []twitter.User
+	if rf, ok := ret.Get(0).(func(*twitter.Tweet, url.Values) []twitter.User); ok {
+		r0 = rf(tw, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]twitter.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*twitter.Tweet, url.Values) error); ok {
+		r1 = rf(tw, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Like provides a mock function with given fields: tw, conf
 func (_m *Client) Like(tw *twitter.Tweet, conf url.Values) error {
 	ret := _m.Called(tw, conf)
@@ -79,6 +210,20 @@ func (_m *Client) Like(tw *twitter.Tweet, conf url.Values) error {
 	return r0
 }
 
+// RemoveBookmark provides a mock function with given fields: tw
+func (_m *Client) RemoveBookmark(tw *twitter.Tweet) error {
+	ret := _m.Called(tw)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*twitter.Tweet) error); ok {
+		r0 = rf(tw)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReTweet provides a mock function with given fields: tw, conf
 func (_m *Client) ReTweet(tw *twitter.Tweet, conf url.Values) error {
 	ret := _m.Called(tw, conf)
@@ -93,6 +238,29 @@ func (_m *Client) ReTweet(tw *twitter.Tweet, conf url.Values) error {
 	return r0
 }
 
+// Retweeters provides a mock function with given fields: tw, conf
+func (_m *Client) Retweeters(tw *twitter.Tweet, conf url.Values) ([]twitter.User, error) {
+	ret := _m.Called(tw, conf)
+
+	var r0 []twitter.User
+	if rf, ok := ret.Get(0).(func(*twitter.Tweet, url.Values) []twitter.User); ok {
+		r0 = rf(tw, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]twitter.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*twitter.Tweet, url.Values) error); ok {
+		r1 = rf(tw, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScreenName provides a mock function with given fields:
 func (_m *Client) ScreenName() string {
 	ret := _m.Called()
@@ -112,6 +280,29 @@ func (_m *Client) SetPollerPaused(b bool) {
 	_m.Called(b)
 }
 
+// ShowStatus provides a mock function with given fields: id, conf
+func (_m *Client) ShowStatus(id string, conf url.Values) (*twitter.Tweet, error) {
+	ret := _m.Called(id, conf)
+
+	var r0 *twitter.Tweet
+	if rf, ok := ret.Get(0).(func(string, url.Values) *twitter.Tweet); ok {
+		r0 = rf(id, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*twitter.Tweet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, url.Values) error); ok {
+		r1 = rf(id, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Shutdown provides a mock function with given fields:
 func (_m *Client) Shutdown() {
 	_m.Called()
@@ -122,6 +313,20 @@ func (_m *Client) StartPoller(tweetCh chan<- []*twitter.Tweet) {
 	_m.Called(tweetCh)
 }
 
+// TimeZone provides a mock function with given fields:
+func (_m *Client) TimeZone() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // UnLike provides a mock function with given fields: tw, conf
 func (_m *Client) UnLike(tw *twitter.Tweet, conf url.Values) error {
 	ret := _m.Called(tw, conf)
@@ -173,6 +378,29 @@ func (_m *Client) UpdateStatus(status string, conf url.Values) (*twitter.Tweet,
 	return r0, r1
 }
 
+// UserShow provides a mock function with given fields: screenName, conf
+func (_m *Client) UserShow(screenName string, conf url.Values) (*twitter.User, error) {
+	ret := _m.Called(screenName, conf)
+
+	var r0 *twitter.User
+	if rf, ok := ret.Get(0).(func(string, url.Values) *twitter.User); ok {
+		r0 = rf(screenName, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*twitter.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, url.Values) error); ok {
+		r1 = rf(screenName, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UserTimeline provides a mock function with given fields: conf
 func (_m *Client) UserTimeline(conf url.Values) ([]*twitter.Tweet, error) {
 	ret := _m.Called(conf)
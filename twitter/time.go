@@ -0,0 +1,106 @@
+package twitter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Setheck/tweetstreem/util"
+)
+
+// the time formats for RelativeTweetTime
+const (
+	TimeFormatRelative  = "relative"  // the time since eg: '1h37m19s ago', then the date
+	TimeFormatHumanized = "humanized" // the time since eg: '3m', then the day eg: 'yesterday 14:02', then the date
+	TimeFormatAbsolute  = "absolute"  // always the date
+)
+
+const (
+	// DefaultRelativeTimeThreshold is how old a tweet can be and still show the time since it was created.
+	DefaultRelativeTimeThreshold = 24 * time.Hour
+	// DefaultAbsoluteTimeThreshold is how old a tweet can be before humanized times show the date.
+	DefaultAbsoluteTimeThreshold = 7 * 24 * time.Hour
+)
+
+// test point
+var timeNow = time.Now
+
+// LoadLocation returns the time zone for an IANA name eg: 'Europe/London', an empty name is local time.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// ParseCreatedAt parses a tweet's created at time.
+func ParseCreatedAt(createdAt string) (time.Time, error) {
+	return time.Parse(CreatedAtTimeLayout, createdAt)
+}
+
+// TimeLocation returns the time zone times are shown in, local time when not set.
+func (c OutputConfig) TimeLocation() *time.Location {
+	if c.Location != nil {
+		return c.Location
+	}
+	return time.Local
+}
+
+// FormatTime returns the time for display, in the configured time zone, time format and thresholds.
+func (c OutputConfig) FormatTime(tm time.Time) string {
+	loc := c.TimeLocation()
+	tm, now := tm.In(loc), timeNow().In(loc)
+	since := now.Sub(tm)
+	relative := parseThreshold(c.RelativeTimeThreshold, DefaultRelativeTimeThreshold)
+	layout := c.AbsoluteTimeLayout
+	if layout == "" {
+		layout = RelativeTweetTimeOutputLayout
+	}
+
+	switch c.TimeFormat {
+	case TimeFormatAbsolute:
+		return tm.Format(layout)
+	case TimeFormatHumanized:
+		switch {
+		case since < relative:
+			return util.HumanizeDuration(since)
+		case since < parseThreshold(c.AbsoluteTimeThreshold, DefaultAbsoluteTimeThreshold):
+			return humanizeDay(tm, now)
+		}
+		return tm.Format(layout)
+	}
+	if since < relative {
+		return since.Truncate(time.Second).String() + " ago"
+	}
+	return tm.Format(layout)
+}
+
+// parseThreshold parses a threshold duration, falling back to the default when it is not set or invalid.
+func parseThreshold(s string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return def
+}
+
+// humanizeDay returns the time with the day relative to now, eg: 'today 14:02', 'yesterday 14:02',
+// 'Mon 14:02' in the last week, 'Jan 2 14:02' this year, otherwise 'Jan 2 2006'.
+func humanizeDay(tm, now time.Time) string {
+	y1, m1, d1 := tm.Date()
+	y2, m2, d2 := now.Date()
+	days := int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	switch {
+	case days == 0:
+		return tm.Format("today 15:04")
+	case days == 1:
+		return tm.Format("yesterday 15:04")
+	case days > 1 && days < 7:
+		return tm.Format("Mon 15:04")
+	case y1 == y2:
+		return tm.Format("Jan 2 15:04")
+	}
+	return tm.Format("Jan 2 2006")
+}
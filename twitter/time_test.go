@@ -0,0 +1,78 @@
+package twitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := LoadLocation("")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	loc, err = LoadLocation("UTC")
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = LoadLocation("Nowhere/Special")
+	assert.Error(t, err)
+}
+
+func TestOutputConfig_FormatTime(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	// wednesday 25th march, 14:30 in UTC-4, which is 18:30 UTC
+	zone := time.FixedZone("UTC-4", -4*60*60)
+	now := time.Date(2020, 3, 25, 14, 30, 0, 0, zone)
+	timeNow = func() time.Time { return now.UTC() }
+	ago := func(d time.Duration) time.Time { return now.Add(-d).UTC() }
+
+	humanized := OutputConfig{TimeFormat: TimeFormatHumanized, Location: zone}
+	tests := []struct {
+		name   string
+		config OutputConfig
+		tm     time.Time
+		want   string
+	}{
+		{"relative", OutputConfig{Location: zone}, ago(10*time.Minute + 30*time.Second), "10m30s ago"},
+		{"relative date", OutputConfig{Location: zone}, ago(25 * time.Hour), "03/24/2020 13:30:00"},
+		{"relative threshold", OutputConfig{Location: zone, RelativeTimeThreshold: "1h"}, ago(2 * time.Hour), "03/25/2020 12:30:00"},
+		{"absolute", OutputConfig{TimeFormat: TimeFormatAbsolute, Location: zone}, ago(time.Minute), "03/25/2020 14:29:00"},
+		{"absolute layout", OutputConfig{TimeFormat: TimeFormatAbsolute, Location: zone, AbsoluteTimeLayout: time.Kitchen}, ago(time.Minute), "2:29PM"},
+		{"humanized seconds", humanized, ago(42 * time.Second), "42s"},
+		{"humanized minutes", humanized, ago(3 * time.Minute), "3m"},
+		{"humanized hours", humanized, ago(5 * time.Hour), "5h"},
+		{"humanized yesterday", humanized, ago(24*time.Hour + 28*time.Minute), "yesterday 14:02"},
+		{"humanized weekday", humanized, ago(3 * 24 * time.Hour), "Sun 14:30"},
+		{"humanized date", humanized, ago(10 * 24 * time.Hour), "03/15/2020 14:30:00"},
+		{"humanized today", OutputConfig{TimeFormat: TimeFormatHumanized, Location: zone, RelativeTimeThreshold: "1h"},
+			ago(2 * time.Hour), "today 12:30"},
+		{"humanized this year", OutputConfig{TimeFormat: TimeFormatHumanized, Location: zone, AbsoluteTimeThreshold: "8760h"},
+			ago(30 * 24 * time.Hour), "Feb 24 14:30"},
+		{"humanized last year", OutputConfig{TimeFormat: TimeFormatHumanized, Location: zone, AbsoluteTimeThreshold: "8760h"},
+			ago(100 * 24 * time.Hour), "Dec 16 2019"},
+		{"invalid threshold", OutputConfig{TimeFormat: TimeFormatHumanized, Location: zone, RelativeTimeThreshold: "soon"},
+			ago(23 * time.Hour), "23h"},
+		{"other time zone", OutputConfig{TimeFormat: TimeFormatAbsolute, Location: time.UTC}, ago(time.Minute), "03/25/2020 18:29:00"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.config.FormatTime(test.tm))
+			tweet := &Tweet{CreatedAt: test.tm.Format(CreatedAtTimeLayout)}
+			assert.Equal(t, test.want, tweet.FormatTime(test.config))
+		})
+	}
+}
+
+func TestTweet_TemplateOutput_TimeFormat(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	now := time.Date(2020, 3, 25, 14, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	tweet := &Tweet{CreatedAt: now.Add(-3 * time.Minute).Format(CreatedAtTimeLayout)}
+	output := tweet.TemplateOutput(OutputConfig{TimeFormat: TimeFormatHumanized})
+	assert.Equal(t, "3m", output.RelativeTweetTime)
+}
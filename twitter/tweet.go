@@ -3,8 +3,12 @@ package twitter
 import (
 	"fmt"
 	"html"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/Setheck/tweetstreem/util"
 )
@@ -32,9 +36,10 @@ type UserMention struct {
 	IDStr      string `json:"id_str"`
 }
 
-// Symbol - from the twitter api
+// Symbol - from the twitter api, a cashtag eg: $TWTR
 type Symbol struct {
-	// TODO
+	Indices []int  `json:"indices"`
+	Text    string `json:"text"`
 }
 
 // URL - from the twitter api
@@ -45,18 +50,62 @@ type URL struct {
 	URL         string `json:"url"`
 }
 
-// Media - from the twitter api
+// MediaSize - from the twitter api
+type MediaSize struct {
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+	Resize string `json:"resize"`
+}
+
+// MediaSizes - from the twitter api
+type MediaSizes struct {
+	Thumb  MediaSize `json:"thumb"`
+	Small  MediaSize `json:"small"`
+	Medium MediaSize `json:"medium"`
+	Large  MediaSize `json:"large"`
+}
+
+// VideoVariant - from the twitter api, bitrate is not set for streaming formats
+type VideoVariant struct {
+	Bitrate     int    `json:"bitrate,omitempty"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
+}
+
+// VideoInfo - from the twitter api, duration is not set for animated gifs
+type VideoInfo struct {
+	AspectRatio    []int          `json:"aspect_ratio"`
+	DurationMillis int            `json:"duration_millis,omitempty"`
+	Variants       []VideoVariant `json:"variants"`
+}
+
+// BestVariant returns the highest bitrate mp4 variant, false if there is none.
+func (v *VideoInfo) BestVariant() (VideoVariant, bool) {
+	var best VideoVariant
+	found := false
+	for _, vv := range v.Variants {
+		if vv.ContentType == "video/mp4" && (!found || vv.Bitrate > best.Bitrate) {
+			best, found = vv, true
+		}
+	}
+	return best, found
+}
+
+// Media - from the twitter api, Type is one of 'photo', 'video' or 'animated_gif'
 type Media struct {
-	DisplayURL    string `json:"display_url"`
-	ExpandedURL   string `json:"expanded_url"`
-	ID            int64  `json:"id"`
-	IDStr         string `json:"id_str"`
-	Indices       []int  `json:"indices"`
-	MediaURL      string `json:"media_url"`
-	MediaURLHTTPS string `json:"media_url_https"`
-	//Sizes - TODO
-	Type string `json:"photo"`
-	URL  string `json:"url"`
+	DisplayURL     string     `json:"display_url"`
+	ExpandedURL    string     `json:"expanded_url"`
+	ID             int64      `json:"id"`
+	IDStr          string     `json:"id_str"`
+	Indices        []int      `json:"indices"`
+	MediaURL       string     `json:"media_url"`
+	MediaURLHTTPS  string     `json:"media_url_https"`
+	Sizes          MediaSizes `json:"sizes"`
+	SourceStatusID *int64     `json:"source_status_id,omitempty"`
+	ExtAltText     *string    `json:"ext_alt_text,omitempty"`
+	Type           string     `json:"type"`
+	URL            string     `json:"url"`
+	VideoInfo      *VideoInfo `json:"video_info,omitempty"`
 }
 
 // Entities - from the twitter api
@@ -66,96 +115,319 @@ type Entities struct {
 	UserMention []UserMention `json:"user_mentions"`
 	Symbol      []Symbol      `json:"symbols"`
 	Media       []Media       `json:"media"`
+	Polls       []Poll        `json:"polls"`
+}
+
+// PollOption - from the twitter api
+type PollOption struct {
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+	Votes    int    `json:"votes"`
+}
+
+// Poll - from the twitter api, vote counts and status are only available from the poll card
+type Poll struct {
+	Options         []PollOption `json:"options"`
+	EndDatetime     string       `json:"end_datetime"`
+	DurationMinutes int          `json:"duration_minutes"`
+	CountsAreFinal  bool         `json:"counts_are_final"`
+}
+
+// PollEndTimeLayout is the golang time layout for the end time of a poll card.
+const PollEndTimeLayout = time.RFC3339
+
+// TotalVotes returns the number of votes across all options.
+func (p *Poll) TotalVotes() int {
+	total := 0
+	for _, o := range p.Options {
+		total += o.Votes
+	}
+	return total
+}
+
+// EndTime returns the time the poll closes, false if it is unknown.
+func (p *Poll) EndTime() (time.Time, bool) {
+	for _, layout := range []string{PollEndTimeLayout, CreatedAtTimeLayout} {
+		if tm, err := time.Parse(layout, p.EndDatetime); err == nil {
+			return tm, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Closed returns whether voting has ended.
+func (p *Poll) Closed() bool {
+	if p.CountsAreFinal {
+		return true
+	}
+	end, ok := p.EndTime()
+	return ok && timeNow().After(end)
+}
+
+// Status returns the poll status for display, eg: 'final results' or '3h20m left'.
+func (p *Poll) Status() string {
+	if p.Closed() {
+		return "final results"
+	}
+	if end, ok := p.EndTime(); ok {
+		return formatTimeLeft(end.Sub(timeNow())) + " left"
+	}
+	return "open"
+}
+
+// formatTimeLeft formats the time left in hours and minutes, eg: '3h20m', '2h' or '45m', under a minute is '<1m'.
+func formatTimeLeft(d time.Duration) string {
+	hours, minutes := int(d/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return "<1m"
+}
+
+// PollBarWidth is the width in characters of a poll option bar.
+const PollBarWidth = 20
+
+// Percentages returns the whole percentage of the votes for each option, rounded by largest remainder
+// so they add up to 100, ties go to the earlier option. All are 0 when there are no votes.
+func (p *Poll) Percentages() []int {
+	pcts := make([]int, len(p.Options))
+	total := p.TotalVotes()
+	if total == 0 {
+		return pcts
+	}
+	order := make([]int, len(p.Options))
+	remaining := 100
+	for i, o := range p.Options {
+		pcts[i] = o.Votes * 100 / total
+		remaining -= pcts[i]
+		order[i] = i
+	}
+	remainder := func(i int) int { return p.Options[i].Votes * 100 % total }
+	sort.SliceStable(order, func(a, b int) bool { return remainder(order[a]) > remainder(order[b]) })
+	for _, i := range order[:remaining] {
+		pcts[i]++
+	}
+	return pcts
+}
+
+// Format renders the poll as a bar chart with percentages.
+func (p *Poll) Format() string {
+	total := p.TotalVotes()
+	labelWidth := 0
+	for _, o := range p.Options {
+		if w := util.StringWidth(o.Text); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	pcts := p.Percentages()
+	out := ""
+	for i, o := range p.Options {
+		pct := pcts[i]
+		filled := pct * PollBarWidth / 100
+		label := util.Pad(o.Text, labelWidth)
+		out += fmt.Sprintf("%s %s%s %3d%% (%d)\n", label,
+			strings.Repeat("█", filled), strings.Repeat("░", PollBarWidth-filled), pct, o.Votes)
+	}
+	return out + fmt.Sprintf("%d votes - %s", total, p.Status())
+}
+
+// CardBindingValue - from the twitter api
+type CardBindingValue struct {
+	Type         string `json:"type"`
+	StringValue  string `json:"string_value"`
+	BooleanValue bool   `json:"boolean_value"`
+}
+
+// Card - from the twitter api, only included when requested with 'include_cards'
+type Card struct {
+	Name          string                      `json:"name"`
+	URL           string                      `json:"url"`
+	BindingValues map[string]CardBindingValue `json:"binding_values"`
 }
 
-// Enrichment - from the twitter api
+// Poll parses the poll from a poll card, eg: 'poll3choice_text_only', nil if this is not a poll card.
+func (c *Card) Poll() *Poll {
+	if !strings.HasPrefix(c.Name, "poll") {
+		return nil
+	}
+	p := &Poll{
+		EndDatetime:    c.BindingValues["end_datetime_utc"].StringValue,
+		CountsAreFinal: c.BindingValues["counts_are_final"].BooleanValue,
+	}
+	p.DurationMinutes, _ = strconv.Atoi(c.BindingValues["duration_minutes"].StringValue)
+	for i := 1; ; i++ {
+		label, ok := c.BindingValues[fmt.Sprintf("choice%d_label", i)]
+		if !ok {
+			break
+		}
+		votes, _ := strconv.Atoi(c.BindingValues[fmt.Sprintf("choice%d_count", i)].StringValue)
+		p.Options = append(p.Options, PollOption{Position: i, Text: label.StringValue, Votes: votes})
+	}
+	if len(p.Options) == 0 {
+		return nil
+	}
+	return p
+}
+
+// DerivedLocation - from the twitter api, the profile geo enrichment of a user
+type DerivedLocation struct {
+	Country     string       `json:"country"`
+	CountryCode string       `json:"country_code"`
+	Locality    string       `json:"locality"`
+	Region      string       `json:"region"`
+	SubRegion   string       `json:"sub_region"`
+	FullName    string       `json:"full_name"`
+	Geo         *Coordinates `json:"geo"`
+}
+
+// Enrichment - from the twitter api, enterprise apis only
 type Enrichment struct {
-	// TODO:
+	Locations []DerivedLocation `json:"locations"`
 }
 
 // User - from the twitter api
 type User struct {
-	ID                   int64        `json:"id"`
-	IDStr                string       `json:"id_str"`
-	Name                 string       `json:"name"`
-	ScreenName           string       `json:"screen_name"`
-	Location             *string      `json:"location"`
-	Derived              []Enrichment `json:"derived"`
-	URL                  *string      `json:"url"`
-	Description          *string      `json:"description"`
-	Protected            bool         `json:"protected"`
-	Verified             bool         `json:"verified"`
-	FollowersCount       int          `json:"followers_count"`
-	FriendsCount         int          `json:"friends_count"`
-	ListedCount          int          `json:"listed_count"`
-	FavouritesCount      int          `json:"favourites_count"`
-	StatusesCount        int          `json:"statuses_count"`
-	CreatedAt            string       `json:"created_at"`
-	ProfileBannerURL     string       `json:"profile_banner_url"`
-	ProfileImageURLHTTPS string       `json:"profile_image_url_https"`
-	DefaultProfile       bool         `json:"default_profile"`
-	DefaultProfileImage  bool         `json:"default_profile_image"`
-	WithheldInCountries  []string     `json:"withheld_in_countries"`
-	WithheldScope        []string     `json:"withheld_scope"`
-}
-
-// ReTweetedStatus - from the twitter api
-type ReTweetedStatus struct {
-	// TODO:
-}
-
-// Coordinates - from the twitter api
+	ID                   int64       `json:"id"`
+	IDStr                string      `json:"id_str"`
+	Name                 string      `json:"name"`
+	ScreenName           string      `json:"screen_name"`
+	Location             *string     `json:"location"`
+	Derived              *Enrichment `json:"derived"`
+	URL                  *string     `json:"url"`
+	Description          *string     `json:"description"`
+	Protected            bool        `json:"protected"`
+	Verified             bool        `json:"verified"`
+	FollowersCount       int         `json:"followers_count"`
+	FriendsCount         int         `json:"friends_count"`
+	ListedCount          int         `json:"listed_count"`
+	FavouritesCount      int         `json:"favourites_count"`
+	StatusesCount        int         `json:"statuses_count"`
+	CreatedAt            string      `json:"created_at"`
+	ProfileBannerURL     string      `json:"profile_banner_url"`
+	ProfileImageURLHTTPS string      `json:"profile_image_url_https"`
+	DefaultProfile       bool        `json:"default_profile"`
+	DefaultProfileImage  bool        `json:"default_profile_image"`
+	WithheldInCountries  []string    `json:"withheld_in_countries"`
+	WithheldScope        []string    `json:"withheld_scope"`
+}
+
+// ReTweetedStatus - from the twitter api, the original tweet that was retweeted
+type ReTweetedStatus = Tweet
+
+// Coordinates - from the twitter api, a geoJSON point as [longitude, latitude]
 type Coordinates struct {
-	// TODO:
+	Coordinates []float64 `json:"coordinates"`
+	Type        string    `json:"type"`
+}
+
+// Longitude returns the longitude of the point
+func (c *Coordinates) Longitude() float64 {
+	if len(c.Coordinates) < 2 {
+		return 0
+	}
+	return c.Coordinates[0]
+}
+
+// Latitude returns the latitude of the point
+func (c *Coordinates) Latitude() float64 {
+	if len(c.Coordinates) < 2 {
+		return 0
+	}
+	return c.Coordinates[1]
+}
+
+// String returns the point as 'latitude,longitude'
+func (c *Coordinates) String() string {
+	return fmt.Sprintf("%g,%g", c.Latitude(), c.Longitude())
+}
+
+// BoundingBox - from the twitter api, a geoJSON polygon of [longitude, latitude] points
+type BoundingBox struct {
+	Coordinates [][][]float64 `json:"coordinates"`
+	Type        string        `json:"type"`
 }
 
 // Place - from the twitter api
 type Place struct {
-	// TODO:
+	ID          string            `json:"id"`
+	URL         string            `json:"url"`
+	PlaceType   string            `json:"place_type"`
+	Name        string            `json:"name"`
+	FullName    string            `json:"full_name"`
+	CountryCode string            `json:"country_code"`
+	Country     string            `json:"country"`
+	BoundingBox *BoundingBox      `json:"bounding_box"`
+	Attributes  map[string]string `json:"attributes"`
 }
 
-// Rule - from the twitter api
+// Rule - from the twitter api, a filtered stream rule that matched the tweet
 type Rule struct {
-	// TODO:
+	Tag   *string `json:"tag"`
+	ID    int64   `json:"id"`
+	IDStr string  `json:"id_str"`
+}
+
+// QuotedStatusPermalink - from the twitter api
+type QuotedStatusPermalink struct {
+	URL      string `json:"url"`
+	Expanded string `json:"expanded"`
+	Display  string `json:"display"`
+}
+
+// CurrentUserRetweet - from the twitter api, only set when the current user retweeted the tweet
+type CurrentUserRetweet struct {
+	ID    int64  `json:"id"`
+	IDStr string `json:"id_str"`
 }
 
 // Tweet - from the twitter api
 type Tweet struct {
-	CreatedAt            string       `json:"created_at"`
-	ID                   int64        `json:"id"`
-	IDStr                string       `json:"id_str"`
-	Text                 string       `json:"text"`
-	FullText             string       `json:"full_text"`
-	Source               string       `json:"source"`
-	Truncated            bool         `json:"truncated"`
-	InReplyToStatusID    *int64       `json:"in_reply_to_status_id"`
-	InReplyToStatusIDStr *string      `json:"in_reply_to_status_id_str"`
-	InReplyToUserID      *int64       `json:"in_reply_to_user_id"`
-	InReplyToUserIDStr   *string      `json:"in_reply_to_user_id_str"`
-	InReplyToScreenName  *string      `json:"in_reply_to_screen_name"`
-	User                 User         `json:"user"`
-	Coordinates          *Coordinates `json:"coordinates"`
-	Place                *Place       `json:"place"`
-	QuotedStatusID       int64        `json:"quoted_status_id"`
-	QuotedStatusIDStr    string       `json:"quoted_status_id_str"`
-	IsQuoteStatus        bool         `json:"is_quote_status"`
-	QuotedStatus         *Tweet       `json:"quoted_status"`
-	ReTweetedStatus      *Tweet       `json:"retweeted_status"`
-	QuoteCount           *int         `json:"quote_count"`
-	ReplyCount           int          `json:"reply_count"`
-	ReTweetCount         int          `json:"retweet_count"`
-	FavoriteCount        int          `json:"favorite_count"`
-	Entities             Entities     `json:"entities"`
-	ExtendedEntities     Entities     `json:"extended_entities"`
-	Favorited            *bool        `json:"favorited"`
-	ReTweeted            bool         `json:"retweeted"`
-	PossiblySensitive    *bool        `json:"possibly_sensitive"`
-	FilterLevel          string       `json:"filter_level"`
-	Lang                 *string      `json:"lang"`
-	MatchingRules        []Rule       `json:"matching_rules"`
-
-	// TODO: Additional Attributes
-	// https://developer.twitter.com/en/docs/tweets/data-dictionary/overview/tweet-object
+	CreatedAt             string                 `json:"created_at"`
+	ID                    int64                  `json:"id"`
+	IDStr                 string                 `json:"id_str"`
+	Text                  string                 `json:"text"`
+	FullText              string                 `json:"full_text"`
+	Source                string                 `json:"source"`
+	Truncated             bool                   `json:"truncated"`
+	InReplyToStatusID     *int64                 `json:"in_reply_to_status_id"`
+	InReplyToStatusIDStr  *string                `json:"in_reply_to_status_id_str"`
+	InReplyToUserID       *int64                 `json:"in_reply_to_user_id"`
+	InReplyToUserIDStr    *string                `json:"in_reply_to_user_id_str"`
+	InReplyToScreenName   *string                `json:"in_reply_to_screen_name"`
+	User                  User                   `json:"user"`
+	Coordinates           *Coordinates           `json:"coordinates"`
+	Place                 *Place                 `json:"place"`
+	QuotedStatusID        int64                  `json:"quoted_status_id"`
+	QuotedStatusIDStr     string                 `json:"quoted_status_id_str"`
+	IsQuoteStatus         bool                   `json:"is_quote_status"`
+	QuotedStatus          *Tweet                 `json:"quoted_status"`
+	ReTweetedStatus       *ReTweetedStatus       `json:"retweeted_status"`
+	QuoteCount            *int                   `json:"quote_count"`
+	ReplyCount            int                    `json:"reply_count"`
+	ReTweetCount          int                    `json:"retweet_count"`
+	FavoriteCount         int                    `json:"favorite_count"`
+	Entities              Entities               `json:"entities"`
+	ExtendedEntities      Entities               `json:"extended_entities"`
+	Favorited             *bool                  `json:"favorited"`
+	ReTweeted             bool                   `json:"retweeted"`
+	PossiblySensitive     *bool                  `json:"possibly_sensitive"`
+	FilterLevel           string                 `json:"filter_level"`
+	Lang                  *string                `json:"lang"`
+	MatchingRules         []Rule                 `json:"matching_rules"`
+	Card                  *Card                  `json:"card,omitempty"`
+	DisplayTextRange      []int                  `json:"display_text_range,omitempty"`
+	QuotedStatusPermalink *QuotedStatusPermalink `json:"quoted_status_permalink,omitempty"`
+	CurrentUserRetweet    *CurrentUserRetweet    `json:"current_user_retweet,omitempty"`
+	WithheldCopyright     bool                   `json:"withheld_copyright,omitempty"`
+	WithheldInCountries   []string               `json:"withheld_in_countries,omitempty"`
+	WithheldScope         string                 `json:"withheld_scope,omitempty"`
+
+	// ref: https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/tweet
 }
 
 // HTMLLink returns the link to the tweet itself
@@ -163,6 +435,39 @@ func (t *Tweet) HTMLLink() string {
 	return fmt.Sprintf(TweetLinkUriTemplate, t.User.ScreenName, t.IDStr)
 }
 
+// statusHosts are the hosts of tweet links
+var statusHosts = []string{"twitter.com", "www.twitter.com", "mobile.twitter.com", "x.com", "www.x.com"}
+
+// ParseStatusURL returns the tweet id from a tweet link eg: 'https://twitter.com/user/status/123'.
+func ParseStatusURL(s string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	known := false
+	for _, host := range statusHosts {
+		known = known || strings.EqualFold(u.Host, host)
+	}
+	// eg: /user/status/123, /user/status/123/photo/1 or /i/web/status/123
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; known && i+1 < len(parts); i++ {
+		if parts[i] == "status" || parts[i] == "statuses" {
+			if _, err := strconv.ParseUint(parts[i+1], 10, 64); err == nil {
+				return parts[i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// Original returns the retweeted status for a retweet, otherwise the tweet itself.
+func (t *Tweet) Original() *Tweet {
+	if t.ReTweetedStatus != nil {
+		return t.ReTweetedStatus
+	}
+	return t
+}
+
 // Links returns relevant links from a tweet
 func (t *Tweet) Links() []string {
 	ulist := make([]string, 0)
@@ -175,6 +480,32 @@ func (t *Tweet) Links() []string {
 	return ulist
 }
 
+// AllMedia returns the media attached to the tweet, extended entities are preferred
+// as they include every photo and the video variants.
+func (t *Tweet) AllMedia() []Media {
+	if len(t.ExtendedEntities.Media) > 0 {
+		return t.ExtendedEntities.Media
+	}
+	return t.Entities.Media
+}
+
+// Poll returns the poll attached to the tweet, or nil if there is none.
+// the poll card is preferred as it includes vote counts.
+func (t *Tweet) Poll() *Poll {
+	if t.Card != nil {
+		if p := t.Card.Poll(); p != nil {
+			return p
+		}
+	}
+	if len(t.Entities.Polls) > 0 {
+		return &t.Entities.Polls[0]
+	}
+	if t.ReTweetedStatus != nil {
+		return t.ReTweetedStatus.Poll()
+	}
+	return nil
+}
+
 // TweetTemplateOutput is the processed object for use with template execution
 type TweetTemplateOutput struct {
 	CreatedAt         string
@@ -185,45 +516,170 @@ type TweetTemplateOutput struct {
 	FavoriteCount     string
 	App               string
 	TweetText         string
+	Poll              string
+	Symbols           []Symbol
+	Media             []Media
+	Place             *Place
+	Coordinates       *Coordinates
+	RetweetedBy       *User                // the retweeting user, nil if not a retweet
+	Original          *TweetTemplateOutput // the retweeted status, nil if not a retweet
+	TweetID           string
+	User              User
+	Verified          bool
+	Lang              string
+	Counts            TweetCounts
+	Entities          Entities
+	ReplyTo           *ReplyTo             // nil if not a reply
+	Quoted            *TweetTemplateOutput // the quoted status, nil if not a quote tweet
+	IsRetweet         bool
+}
+
+// TweetCounts are the engagement counts of a tweet, for use in templates.
+type TweetCounts struct {
+	Retweets  int
+	Favorites int
+	Replies   int
+	Quotes    int
+}
+
+// ReplyTo describes the tweet that a tweet replies to, for use in templates.
+type ReplyTo struct {
+	ScreenName string
+	StatusID   string
+	UserID     string
 }
 
 // OutputConfig is the configuration for outputting text from a tweet
 type OutputConfig struct {
-	MentionHighlightColor string
-	HashtagHighlightColor string
-	Highlight             bool
+	MentionHighlightColor     string
+	HashtagHighlightColor     string
+	URLHighlightColor         string
+	CashtagHighlightColor     string
+	MediaHighlightColor       string // when set, media links are shown as a placeholder eg: '[photo]'
+	SelfMentionHighlightColor string // mentions of ScreenName, falls back to MentionHighlightColor
+	Highlight                 bool
+	LinkFormat                string         // one of LinkFormatShort, LinkFormatExpanded or LinkFormatDisplay
+	TimeFormat                string         // one of TimeFormatRelative, TimeFormatHumanized or TimeFormatAbsolute
+	TimeZone                  string         // IANA name eg: 'Europe/London', falls back to the account's time zone
+	RelativeTimeThreshold     string         // newer tweets show the time since, eg: '24h'
+	AbsoluteTimeThreshold     string         // older tweets show the date with humanized times, eg: '168h'
+	AbsoluteTimeLayout        string         // the go time layout for dates, defaults to RelativeTweetTimeOutputLayout
+	ScreenName                string         `json:"-"` // the authenticated user, set at runtime
+	Location                  *time.Location `json:"-"` // the resolved time zone, set at runtime
+}
+
+// mentionColor returns the highlight color for a mention of the given screen name.
+func (c OutputConfig) mentionColor(screenName string) string {
+	if c.SelfMentionHighlightColor != "" && c.ScreenName != "" && strings.EqualFold(screenName, c.ScreenName) {
+		return c.SelfMentionHighlightColor
+	}
+	return c.MentionHighlightColor
+}
+
+// highlightColor returns the color if highlighting is enabled, otherwise no color.
+func (c OutputConfig) highlightColor(color string) string {
+	if c.Highlight {
+		return color
+	}
+	return ""
 }
 
 // TemplateOutput returns a TweetTemplateOutput based on the given tweet,
 // this object should be used with the template library as an object for execution
+//
+// For a retweet the output describes the original status, with the retweeting user in RetweetedBy.
 func (t *Tweet) TemplateOutput(config OutputConfig) TweetTemplateOutput {
+	if t.ReTweetedStatus != nil {
+		original := t.ReTweetedStatus.TemplateOutput(config)
+		output := original
+		retweetedBy := t.User
+		output.RetweetedBy = &retweetedBy
+		output.Original = &original
+		output.IsRetweet = true
+		return output
+	}
+	var quoted *TweetTemplateOutput
+	if t.QuotedStatus != nil {
+		q := t.QuotedStatus.TemplateOutput(config)
+		quoted = &q
+	}
 	return TweetTemplateOutput{
 		CreatedAt:         t.CreatedAt,
 		UserName:          t.User.Name,
 		ScreenName:        t.User.ScreenName,
-		RelativeTweetTime: t.RelativeTweetTime(),
+		RelativeTweetTime: t.FormatTime(config),
 		ReTweetCount:      strconv.Itoa(t.ReTweetCount),
 		FavoriteCount:     strconv.Itoa(t.FavoriteCount),
 		App:               util.ExtractAnchorText(t.Source),
 		TweetText:         t.TweetText(config),
+		Poll:              t.formatPoll(),
+		Symbols:           t.Entities.Symbol,
+		Media:             t.AllMedia(),
+		Place:             t.Place,
+		Coordinates:       t.Coordinates,
+		TweetID:           t.IDStr,
+		User:              t.User,
+		Verified:          t.User.Verified,
+		Lang:              stringValue(t.Lang),
+		Counts: TweetCounts{
+			Retweets:  t.ReTweetCount,
+			Favorites: t.FavoriteCount,
+			Replies:   t.ReplyCount,
+			Quotes:    intValue(t.QuoteCount),
+		},
+		Entities: t.Entities,
+		ReplyTo:  t.replyTo(),
+		Quoted:   quoted,
+	}
+}
+
+func (t *Tweet) replyTo() *ReplyTo {
+	if t.InReplyToStatusIDStr == nil && t.InReplyToScreenName == nil {
+		return nil
+	}
+	return &ReplyTo{
+		ScreenName: stringValue(t.InReplyToScreenName),
+		StatusID:   stringValue(t.InReplyToStatusIDStr),
+		UserID:     stringValue(t.InReplyToUserIDStr),
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func (t *Tweet) formatPoll() string {
+	if p := t.Poll(); p != nil {
+		return p.Format()
 	}
+	return ""
 }
 
 // RelativeTweetTime returns a string output for display
-//  if the tweet happened < 24 hours ago, then the relative time is 'XhYmZs ago'
-//  otherwise the RelativeTweetTimeOutputLayout is used for time formatting.
+//
+//	if the tweet happened < 24 hours ago, then the relative time is 'XhYmZs ago'
+//	otherwise the RelativeTweetTimeOutputLayout is used for time formatting.
 func (t *Tweet) RelativeTweetTime() string {
-	tstr := t.CreatedAt
-	tm, err := time.Parse(CreatedAtTimeLayout, t.CreatedAt)
-	if err == nil {
-		since := time.Since(tm)
-		if since < time.Hour*24 {
-			tstr = since.Truncate(time.Second).String() + " ago"
-		} else {
-			tstr = tm.Format(RelativeTweetTimeOutputLayout)
-		}
+	return t.FormatTime(OutputConfig{})
+}
+
+// FormatTime returns the time the tweet was created for display, see OutputConfig.FormatTime.
+func (t *Tweet) FormatTime(config OutputConfig) string {
+	tm, err := ParseCreatedAt(t.CreatedAt)
+	if err != nil {
+		return t.CreatedAt
 	}
-	return tstr
+	return config.FormatTime(tm)
 }
 
 func (t *Tweet) formatRetweetText(config OutputConfig) string {
@@ -245,26 +701,153 @@ func (t *Tweet) TweetText(config OutputConfig) string {
 	if len(t.FullText) > 0 {
 		text = t.FullText
 	}
+	var ents []textEntity
 	if config.Highlight {
-		var hlents util.HighlightEntityList
 		for _, ht := range t.Entities.HashTags {
-			start, end := ht.Indices[0], ht.Indices[1]
-			hlents = append(hlents, util.HighlightEntity{
-				StartIdx: start,
-				EndIdx:   end,
-				Color:    config.HashtagHighlightColor})
+			ents = appendTextEntity(ents, ht.Indices, nil, config.HashtagHighlightColor)
 		}
-
 		for _, um := range t.Entities.UserMention {
-			start, end := um.Indices[0], um.Indices[1]
-			hlents = append(hlents, util.HighlightEntity{
-				StartIdx: start,
-				EndIdx:   end,
-				Color:    config.MentionHighlightColor})
+			ents = appendTextEntity(ents, um.Indices, nil, config.mentionColor(um.ScreenName))
 		}
-		text = util.HighlightEntities(text, hlents)
+		for _, sym := range t.Entities.Symbol {
+			ents = appendTextEntity(ents, sym.Indices, nil, config.CashtagHighlightColor)
+		}
+	}
+	ents = append(ents, t.linkEntities(config)...)
+	// entity indices count the unescaped text, eg: '&amp;' is a single character
+	return applyTextEntities(html.UnescapeString(text), ents)
+}
+
+// Link formats for displaying the links in tweet text
+const (
+	// LinkFormatShort leaves the t.co links as they are
+	LinkFormatShort = ""
+	// LinkFormatExpanded replaces the t.co links with the full url
+	LinkFormatExpanded = "expanded"
+	// LinkFormatDisplay replaces the t.co links with the shortened url twitter displays
+	LinkFormatDisplay = "display"
+)
+
+// linkEntities returns the entities replacing and highlighting t.co links, according to the configured link format.
+// media links are removed, since the media is not part of the text, unless a media highlight color
+// is configured, then they are shown as a placeholder.
+func (t *Tweet) linkEntities(config OutputConfig) []textEntity {
+	var ents []textEntity
+	urlColor := config.highlightColor(config.URLHighlightColor)
+	for _, u := range t.Entities.Urls {
+		link := u.ExpandedURL
+		if config.LinkFormat == LinkFormatDisplay {
+			link = u.DisplayURL
+		}
+		switch {
+		case config.LinkFormat != LinkFormatShort && link != "":
+			ents = appendTextEntity(ents, u.Indices, &link, urlColor)
+		case urlColor != "":
+			ents = appendTextEntity(ents, u.Indices, nil, urlColor)
+		}
+	}
+	mediaColor := config.highlightColor(config.MediaHighlightColor)
+	placeholder := t.mediaPlaceholder()
+	stripped := ""
+	for _, m := range t.Entities.Media {
+		switch {
+		case mediaColor != "" && config.LinkFormat == LinkFormatShort:
+			ents = appendTextEntity(ents, m.Indices, nil, mediaColor)
+		case mediaColor != "":
+			ents = appendTextEntity(ents, m.Indices, &placeholder, mediaColor)
+		case config.LinkFormat != LinkFormatShort:
+			ents = appendTextEntity(ents, m.Indices, &stripped, "")
+		}
+	}
+	return ents
+}
+
+// mediaPlaceholder describes the attached media, eg: '[photo]', '[3 photos]' or '[video]'
+func (t *Tweet) mediaPlaceholder() string {
+	media := t.AllMedia()
+	if len(media) == 0 {
+		return "[media]"
+	}
+	label := mediaLabel(media[0].Type)
+	for _, m := range media[1:] {
+		if mediaLabel(m.Type) != label {
+			label = "media"
+		}
+	}
+	switch {
+	case len(media) == 1:
+		return fmt.Sprintf("[%s]", label)
+	case label == "media":
+		return fmt.Sprintf("[%d media]", len(media))
+	}
+	return fmt.Sprintf("[%d %ss]", len(media), label)
+}
+
+func mediaLabel(mediaType string) string {
+	switch mediaType {
+	case "animated_gif":
+		return "gif"
+	case "":
+		return "media"
+	}
+	return mediaType
+}
+
+// textEntity is a span of utf-16 indices in the tweet text to be replaced and/or highlighted.
+type textEntity struct {
+	start, end int
+	replace    *string
+	color      string
+}
+
+func appendTextEntity(ents []textEntity, indices []int, replace *string, color string) []textEntity {
+	if len(indices) < 2 {
+		return ents
+	}
+	return append(ents, textEntity{start: indices[0], end: indices[1], replace: replace, color: color})
+}
+
+// applyTextEntities replaces and highlights the entities in the text, following the same rules
+// as util.HighlightEntities, invalid or overlapping entities are skipped.
+func applyTextEntities(text string, ents []textEntity) string {
+	sort.SliceStable(ents, func(i, j int) bool {
+		if ents[i].start == ents[j].start {
+			return ents[i].end > ents[j].end
+		}
+		return ents[i].start < ents[j].start
+	})
+	utext := util.NewUTF16Text(text)
+	var sb strings.Builder
+	var hlents util.HighlightEntityList
+	curIdx, outIdx, stripped := 0, 0, false
+	for _, e := range ents {
+		if e.start < curIdx || e.start >= e.end || !utext.IsBoundary(e.start) || !utext.IsBoundary(e.end) {
+			continue
+		}
+		sb.WriteString(utext.Slice(curIdx, e.start))
+		outIdx += e.start - curIdx
+		segment, segmentLen := utext.Slice(e.start, e.end), e.end-e.start
+		if e.replace != nil {
+			segment, segmentLen = *e.replace, util.UTF16Len(*e.replace)
+			stripped = stripped || segmentLen == 0
+		}
+		if e.color != "" {
+			hlents = append(hlents, util.HighlightEntity{StartIdx: outIdx, EndIdx: outIdx + segmentLen, Color: e.color})
+		}
+		sb.WriteString(segment)
+		outIdx += segmentLen
+		curIdx = e.end
+	}
+	sb.WriteString(utext.Slice(curIdx, utext.Len()))
+	result := sb.String()
+	if stripped {
+		// a removed media link leaves the whitespace that preceded it
+		result = strings.TrimRightFunc(result, unicode.IsSpace)
+	}
+	if len(hlents) > 0 {
+		result = util.HighlightEntities(result, hlents)
 	}
-	return html.UnescapeString(text)
+	return result
 }
 
 // SleepTime - from the twitter api
@@ -1,10 +1,14 @@
 package twitter
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/Setheck/tweetstreem/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -245,6 +249,604 @@ func TestTweet_TweetText(t *testing.T) {
 	}
 }
 
+func TestTweet_TweetText_Links(t *testing.T) {
+	text := "see https://t.co/abc123 for #news ❤ @User https://t.co/media1"
+	tweet := &Tweet{
+		FullText: text,
+		Entities: Entities{
+			Urls: []URL{{
+				URL:         "https://t.co/abc123",
+				ExpandedURL: "https://example.com/some/long/path",
+				DisplayURL:  "example.com/some/lo…",
+				Indices:     []int{4, 23},
+			}},
+			HashTags:    []HashTag{{Text: "news", Indices: []int{28, 33}}},
+			UserMention: []UserMention{{ScreenName: "User", Indices: []int{36, 41}}},
+			Media:       []Media{{URL: "https://t.co/media1", Indices: []int{42, 61}}},
+		},
+	}
+	blue := func(s string) string { return util.Colors.Colorize("blue", s) }
+	red := func(s string) string { return util.Colors.Colorize("red", s) }
+
+	tests := []struct {
+		name   string
+		config OutputConfig
+		want   string
+	}{
+		{"short links", OutputConfig{}, text},
+		{"expanded links", OutputConfig{LinkFormat: LinkFormatExpanded},
+			"see https://example.com/some/long/path for #news ❤ @User"},
+		{"display links", OutputConfig{LinkFormat: LinkFormatDisplay},
+			"see example.com/some/lo… for #news ❤ @User"},
+		{"expanded links highlighted", OutputConfig{
+			LinkFormat:            LinkFormatExpanded,
+			Highlight:             true,
+			HashtagHighlightColor: "blue",
+			MentionHighlightColor: "red",
+		}, "see https://example.com/some/long/path for " + blue("#news") + " ❤ " + red("@User")},
+		{"short links highlighted", OutputConfig{
+			Highlight:             true,
+			HashtagHighlightColor: "blue",
+			MentionHighlightColor: "red",
+		}, "see https://t.co/abc123 for " + blue("#news") + " ❤ " + red("@User") + " https://t.co/media1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, tweet.TweetText(test.config))
+		})
+	}
+}
+
+func TestTweet_TweetText_Highlight(t *testing.T) {
+	text := "$TWTR up, see https://t.co/abc123 @Me @you https://t.co/media1"
+	tweet := &Tweet{
+		FullText: text,
+		Entities: Entities{
+			Symbol: []Symbol{{Text: "TWTR", Indices: []int{0, 5}}},
+			Urls: []URL{{
+				URL:         "https://t.co/abc123",
+				ExpandedURL: "https://example.com",
+				DisplayURL:  "example.com",
+				Indices:     []int{14, 33},
+			}},
+			UserMention: []UserMention{
+				{ScreenName: "me", Indices: []int{34, 37}},
+				{ScreenName: "you", Indices: []int{38, 42}},
+			},
+			Media: []Media{{URL: "https://t.co/media1", Type: "photo", Indices: []int{43, 62}}},
+		},
+	}
+	color := func(c, s string) string { return util.Colors.Colorize(c, s) }
+	all := OutputConfig{
+		Highlight:                 true,
+		MentionHighlightColor:     "blue",
+		CashtagHighlightColor:     "green",
+		URLHighlightColor:         "cyan",
+		MediaHighlightColor:       "yellow",
+		SelfMentionHighlightColor: "red",
+		ScreenName:                "ME",
+	}
+	with := func(f func(c *OutputConfig)) OutputConfig {
+		c := all
+		f(&c)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		config OutputConfig
+		want   string
+	}{
+		{"short links", all,
+			color("green", "$TWTR") + " up, see " + color("cyan", "https://t.co/abc123") + " " +
+				color("red", "@Me") + " " + color("blue", "@you") + " " + color("yellow", "https://t.co/media1")},
+		{"expanded links", with(func(c *OutputConfig) { c.LinkFormat = LinkFormatExpanded }),
+			color("green", "$TWTR") + " up, see " + color("cyan", "https://example.com") + " " +
+				color("red", "@Me") + " " + color("blue", "@you") + " " + color("yellow", "[photo]")},
+		{"display links", with(func(c *OutputConfig) { c.LinkFormat = LinkFormatDisplay }),
+			color("green", "$TWTR") + " up, see " + color("cyan", "example.com") + " " +
+				color("red", "@Me") + " " + color("blue", "@you") + " " + color("yellow", "[photo]")},
+		{"no self mention color", with(func(c *OutputConfig) { c.SelfMentionHighlightColor = "" }),
+			color("green", "$TWTR") + " up, see " + color("cyan", "https://t.co/abc123") + " " +
+				color("blue", "@Me") + " " + color("blue", "@you") + " " + color("yellow", "https://t.co/media1")},
+		{"unknown screen name", with(func(c *OutputConfig) { c.ScreenName = "" }),
+			color("green", "$TWTR") + " up, see " + color("cyan", "https://t.co/abc123") + " " +
+				color("blue", "@Me") + " " + color("blue", "@you") + " " + color("yellow", "https://t.co/media1")},
+		{"no url or media color", with(func(c *OutputConfig) {
+			c.LinkFormat = LinkFormatExpanded
+			c.URLHighlightColor = ""
+			c.MediaHighlightColor = ""
+		}), color("green", "$TWTR") + " up, see https://example.com " + color("red", "@Me") + " " + color("blue", "@you")},
+		{"highlight disabled", with(func(c *OutputConfig) {
+			c.Highlight = false
+			c.LinkFormat = LinkFormatExpanded
+		}), "$TWTR up, see https://example.com @Me @you"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, tweet.TweetText(test.config))
+		})
+	}
+}
+
+func TestTweet_MediaPlaceholder(t *testing.T) {
+	tests := []struct {
+		name  string
+		media []Media
+		want  string
+	}{
+		{"none", nil, "[media]"},
+		{"photo", []Media{{Type: "photo"}}, "[photo]"},
+		{"photos", []Media{{Type: "photo"}, {Type: "photo"}, {Type: "photo"}}, "[3 photos]"},
+		{"video", []Media{{Type: "video"}}, "[video]"},
+		{"gif", []Media{{Type: "animated_gif"}}, "[gif]"},
+		{"unknown", []Media{{}}, "[media]"},
+		{"unknowns", []Media{{}, {}}, "[2 media]"},
+		{"mixed", []Media{{Type: "photo"}, {Type: "video"}}, "[2 media]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tweet := &Tweet{ExtendedEntities: Entities{Media: test.media}}
+			assert.Equal(t, test.want, tweet.mediaPlaceholder())
+		})
+	}
+}
+
+func TestTweet_TweetText_Unicode(t *testing.T) {
+	highlight := OutputConfig{Highlight: true, HashtagHighlightColor: "blue", MentionHighlightColor: "red", LinkFormat: LinkFormatExpanded}
+	blue := func(s string) string { return util.Colors.Colorize("blue", s) }
+	red := func(s string) string { return util.Colors.Colorize("red", s) }
+
+	tests := []struct {
+		name  string
+		tweet *Tweet
+		want  string
+	}{
+		{"html entities", &Tweet{
+			FullText: "Tom &amp; Jerry &lt;3 #cartoon",
+			Entities: Entities{HashTags: []HashTag{{Text: "cartoon", Indices: []int{15, 23}}}},
+		}, "Tom & Jerry <3 " + blue("#cartoon")},
+		{"emoji", &Tweet{
+			FullText: "🎉🎉 party with @friend #weekend 🍕",
+			Entities: Entities{
+				UserMention: []UserMention{{ScreenName: "friend", Indices: []int{16, 23}}},
+				HashTags:    []HashTag{{Text: "weekend", Indices: []int{24, 32}}},
+			},
+		}, "🎉🎉 party with " + red("@friend") + " " + blue("#weekend") + " 🍕"},
+		{"cjk", &Tweet{
+			FullText: "東京タワーなう #東京 @tokyo",
+			Entities: Entities{
+				HashTags:    []HashTag{{Text: "東京", Indices: []int{8, 11}}},
+				UserMention: []UserMention{{ScreenName: "tokyo", Indices: []int{12, 18}}},
+			},
+		}, "東京タワーなう " + blue("#東京") + " " + red("@tokyo")},
+		{"emoji and link", &Tweet{
+			FullText: "👀 https://t.co/abc &amp; #news",
+			Entities: Entities{
+				Urls:     []URL{{ExpandedURL: "https://example.com", Indices: []int{3, 19}}},
+				HashTags: []HashTag{{Text: "news", Indices: []int{22, 27}}},
+			},
+		}, "👀 https://example.com & " + blue("#news")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.tweet.TweetText(highlight))
+		})
+	}
+}
+
+func TestApplyTextEntities_Invalid(t *testing.T) {
+	replacement := "X"
+	tests := []struct {
+		name string
+		ents []textEntity
+		want string
+	}{
+		{"end past text", []textEntity{{start: 2, end: 50, replace: &replacement}}, "some text"},
+		{"start after end", []textEntity{{start: 4, end: 2, replace: &replacement}}, "some text"},
+		{"overlapping", []textEntity{
+			{start: 0, end: 4, replace: &replacement},
+			{start: 2, end: 6, replace: &replacement},
+		}, "X text"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, applyTextEntities("some text", test.ents))
+		})
+	}
+}
+
+func TestTweet_Poll(t *testing.T) {
+	cardTweet := []byte(`{"id_str":"1","card":{"name":"poll3choice_text_only","url":"https://t.co/x","binding_values":{
+		"choice1_label":{"type":"STRING","string_value":"A"},"choice1_count":{"type":"STRING","string_value":"6"},
+		"choice2_label":{"type":"STRING","string_value":"B"},"choice2_count":{"type":"STRING","string_value":"3"},
+		"choice3_label":{"type":"STRING","string_value":"C"},"choice3_count":{"type":"STRING","string_value":"1"},
+		"end_datetime_utc":{"type":"STRING","string_value":"2020-03-10T01:00:00Z"},
+		"counts_are_final":{"type":"BOOLEAN","boolean_value":true},
+		"duration_minutes":{"type":"STRING","string_value":"1440"}}}}`)
+	entitiesTweet := []byte(`{"id_str":"2","entities":{"polls":[{"options":[{"position":1,"text":"yes"},{"position":2,"text":"no"}],
+		"end_datetime":"Tue Mar 10 01:00:00 +0000 2020","duration_minutes":60}]}}`)
+	linkCardTweet := []byte(`{"id_str":"3","card":{"name":"summary","binding_values":{}}}`)
+
+	tests := []struct {
+		name string
+		data []byte
+		want *Poll
+	}{
+		{"poll card", cardTweet, &Poll{
+			Options: []PollOption{
+				{Position: 1, Text: "A", Votes: 6},
+				{Position: 2, Text: "B", Votes: 3},
+				{Position: 3, Text: "C", Votes: 1},
+			},
+			EndDatetime:     "2020-03-10T01:00:00Z",
+			DurationMinutes: 1440,
+			CountsAreFinal:  true,
+		}},
+		{"entities poll", entitiesTweet, &Poll{
+			Options: []PollOption{
+				{Position: 1, Text: "yes"},
+				{Position: 2, Text: "no"},
+			},
+			EndDatetime:     "Tue Mar 10 01:00:00 +0000 2020",
+			DurationMinutes: 60,
+		}},
+		{"non poll card", linkCardTweet, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tweet := &Tweet{}
+			assert.NoError(t, json.Unmarshal(test.data, tweet))
+			assert.Equal(t, test.want, tweet.Poll())
+
+			retweet := &Tweet{ReTweetedStatus: tweet}
+			assert.Equal(t, test.want, retweet.Poll())
+		})
+	}
+}
+
+func TestPoll_Format(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	now := time.Date(2020, 3, 25, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	closed := &Poll{
+		Options: []PollOption{
+			{Position: 1, Text: "A", Votes: 6},
+			{Position: 2, Text: "Bee", Votes: 3},
+			{Position: 3, Text: "C", Votes: 1},
+		},
+		EndDatetime: "2020-03-10T01:00:00Z",
+	}
+	assert.True(t, closed.Closed())
+	assert.Equal(t, 10, closed.TotalVotes())
+	assert.Equal(t, ""+
+		"A   ████████████░░░░░░░░  60% (6)\n"+
+		"Bee ██████░░░░░░░░░░░░░░  30% (3)\n"+
+		"C   ██░░░░░░░░░░░░░░░░░░  10% (1)\n"+
+		"10 votes - final results", closed.Format())
+
+	thirds := &Poll{
+		Options: []PollOption{
+			{Position: 1, Text: "A", Votes: 1},
+			{Position: 2, Text: "B", Votes: 1},
+			{Position: 3, Text: "C", Votes: 1},
+		},
+		CountsAreFinal: true,
+	}
+	assert.Equal(t, ""+
+		"A ██████░░░░░░░░░░░░░░  34% (1)\n"+
+		"B ██████░░░░░░░░░░░░░░  33% (1)\n"+
+		"C ██████░░░░░░░░░░░░░░  33% (1)\n"+
+		"3 votes - final results", thirds.Format(), "the percentages add up to 100")
+
+	open := &Poll{
+		Options: []PollOption{
+			{Position: 1, Text: "yes"},
+			{Position: 2, Text: "no"},
+		},
+		EndDatetime: now.Add(3*time.Hour + 20*time.Minute + 30*time.Second).Format(PollEndTimeLayout),
+	}
+	assert.False(t, open.Closed())
+	assert.Equal(t, ""+
+		"yes ░░░░░░░░░░░░░░░░░░░░   0% (0)\n"+
+		"no  ░░░░░░░░░░░░░░░░░░░░   0% (0)\n"+
+		"0 votes - 3h20m left", open.Format())
+
+	wide := &Poll{
+		Options: []PollOption{
+			{Position: 1, Text: "はい", Votes: 1},
+			{Position: 2, Text: "no", Votes: 1},
+		},
+		CountsAreFinal: true,
+	}
+	assert.Equal(t, ""+
+		"はい ██████████░░░░░░░░░░  50% (1)\n"+
+		"no   ██████████░░░░░░░░░░  50% (1)\n"+
+		"2 votes - final results", wide.Format(), "wide labels are aligned by terminal width")
+
+	unknownEnd := &Poll{}
+	assert.False(t, unknownEnd.Closed())
+	assert.Equal(t, "open", unknownEnd.Status())
+}
+
+func TestPoll_Status(t *testing.T) {
+	nowSave := timeNow
+	defer func() { timeNow = nowSave }()
+	now := time.Date(2020, 3, 25, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	tests := []struct {
+		name string
+		poll *Poll
+		want string
+	}{
+		{"hours and minutes", &Poll{EndDatetime: now.Add(3*time.Hour + 20*time.Minute).Format(PollEndTimeLayout)}, "3h20m left"},
+		{"hours", &Poll{EndDatetime: now.Add(2*time.Hour + 30*time.Second).Format(PollEndTimeLayout)}, "2h left"},
+		{"minutes", &Poll{EndDatetime: now.Add(45 * time.Minute).Format(PollEndTimeLayout)}, "45m left"},
+		{"seconds", &Poll{EndDatetime: now.Add(30 * time.Second).Format(PollEndTimeLayout)}, "<1m left"},
+		{"days", &Poll{EndDatetime: now.Add(50*time.Hour + 5*time.Minute).Format(PollEndTimeLayout)}, "50h5m left"},
+		{"ended", &Poll{EndDatetime: now.Add(-time.Minute).Format(PollEndTimeLayout)}, "final results"},
+		{"final", &Poll{CountsAreFinal: true}, "final results"},
+		{"unknown end", &Poll{}, "open"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.poll.Status())
+		})
+	}
+}
+
+func TestPoll_Percentages(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes []int
+		want  []int
+	}{
+		{"exact", []int{6, 3, 1}, []int{60, 30, 10}},
+		{"thirds", []int{1, 1, 1}, []int{34, 33, 33}},
+		{"largest remainder", []int{1, 2, 4}, []int{14, 29, 57}},
+		{"sixths", []int{1, 1, 1, 1, 1, 1}, []int{17, 17, 17, 17, 16, 16}},
+		{"no votes", []int{0, 0}, []int{0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Poll{}
+			for i, v := range test.votes {
+				p.Options = append(p.Options, PollOption{Position: i + 1, Votes: v})
+			}
+			assert.Equal(t, test.want, p.Percentages())
+		})
+	}
+}
+
+func TestTweet_TemplateOutput_Retweet(t *testing.T) {
+	tweet := loadTweetFixture(t, filepath.Join("testdata", "retweet.json"))
+	original := tweet.ReTweetedStatus
+	config := OutputConfig{LinkFormat: LinkFormatExpanded}
+
+	output := tweet.TemplateOutput(config)
+	want := original.TemplateOutput(config)
+	if assert.NotNil(t, output.RetweetedBy) {
+		assert.Equal(t, tweet.User.ScreenName, output.RetweetedBy.ScreenName)
+	}
+	if assert.NotNil(t, output.Original) {
+		assert.Equal(t, want, *output.Original)
+	}
+	assert.Equal(t, original.User.ScreenName, output.ScreenName)
+	assert.Equal(t, original.User.Name, output.UserName)
+	assert.Equal(t, "402", output.FavoriteCount)
+	assert.Equal(t, want.ReTweetCount, output.ReTweetCount)
+	assert.Equal(t, want.RelativeTweetTime, output.RelativeTweetTime)
+	assert.Equal(t, want.App, output.App)
+	assert.Equal(t, want.TweetText, output.TweetText)
+	assert.NotContains(t, output.TweetText, "RT @")
+
+	assert.Nil(t, want.RetweetedBy)
+	assert.Nil(t, want.Original)
+}
+
+func TestTweet_TemplateOutput_Structured(t *testing.T) {
+	lang, replyTo, replyToID, replyToUserID := "en", "someone", "99", "42"
+	quotes := 4
+	tweet := &Tweet{
+		IDStr:                "123",
+		User:                 User{ScreenName: "author", Verified: true, FollowersCount: 1200},
+		Lang:                 &lang,
+		ReTweetCount:         1,
+		FavoriteCount:        2,
+		ReplyCount:           3,
+		QuoteCount:           &quotes,
+		InReplyToScreenName:  &replyTo,
+		InReplyToStatusIDStr: &replyToID,
+		InReplyToUserIDStr:   &replyToUserID,
+		Entities:             Entities{HashTags: []HashTag{{Text: "go"}}},
+		QuotedStatus:         &Tweet{IDStr: "456", User: User{ScreenName: "quoted"}},
+	}
+	output := tweet.TemplateOutput(OutputConfig{})
+	assert.Equal(t, "123", output.TweetID)
+	assert.Equal(t, tweet.User, output.User)
+	assert.True(t, output.Verified)
+	assert.Equal(t, "en", output.Lang)
+	assert.Equal(t, TweetCounts{Retweets: 1, Favorites: 2, Replies: 3, Quotes: 4}, output.Counts)
+	assert.Equal(t, tweet.Entities, output.Entities)
+	assert.Equal(t, &ReplyTo{ScreenName: "someone", StatusID: "99", UserID: "42"}, output.ReplyTo)
+	if assert.NotNil(t, output.Quoted) {
+		assert.Equal(t, "456", output.Quoted.TweetID)
+		assert.Equal(t, "quoted", output.Quoted.ScreenName)
+	}
+	assert.False(t, output.IsRetweet)
+
+	empty := (&Tweet{}).TemplateOutput(OutputConfig{})
+	assert.Nil(t, empty.ReplyTo)
+	assert.Nil(t, empty.Quoted)
+	assert.Empty(t, empty.Lang)
+	assert.Equal(t, TweetCounts{}, empty.Counts)
+
+	retweet := (&Tweet{IDStr: "789", ReTweetedStatus: tweet}).TemplateOutput(OutputConfig{})
+	assert.True(t, retweet.IsRetweet)
+	assert.Equal(t, "123", retweet.TweetID)
+}
+
+func TestParseStatusURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		wantID string
+		wantOk bool
+	}{
+		{"https://twitter.com/user/status/123", "123", true},
+		{"https://x.com/user/status/123?s=20", "123", true},
+		{"http://mobile.twitter.com/user/status/123/photo/1", "123", true},
+		{"https://twitter.com/i/web/status/123", "123", true},
+		{"https://twitter.com/user", "", false},
+		{"https://twitter.com/user/status/abc", "", false},
+		{"https://example.com/user/status/123", "", false},
+		{"twitter.com/user/status/123", "", false},
+		{"123", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			id, ok := ParseStatusURL(test.url)
+			assert.Equal(t, test.wantID, id)
+			assert.Equal(t, test.wantOk, ok)
+		})
+	}
+}
+
+func TestTweet_Original(t *testing.T) {
+	tweet := &Tweet{IDStr: "1"}
+	assert.Same(t, tweet, tweet.Original())
+	retweet := &Tweet{IDStr: "2", ReTweetedStatus: tweet}
+	assert.Same(t, tweet, retweet.Original())
+}
+
+func TestTweet_TemplateOutput_Poll(t *testing.T) {
+	tweet := &Tweet{Entities: Entities{Polls: []Poll{{
+		Options:        []PollOption{{Text: "yes", Votes: 1}, {Text: "no", Votes: 1}},
+		CountsAreFinal: true,
+	}}}}
+	assert.Equal(t, tweet.Poll().Format(), tweet.TemplateOutput(OutputConfig{}).Poll)
+	assert.Empty(t, (&Tweet{}).TemplateOutput(OutputConfig{}).Poll)
+}
+
+func TestTweet_JSONRoundTrip(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, fixtures)
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			tweet := loadTweetFixture(t, fixture)
+			data, err := json.Marshal(tweet)
+			assert.NoError(t, err)
+			roundTrip := &Tweet{}
+			assert.NoError(t, json.Unmarshal(data, roundTrip))
+			assert.Equal(t, tweet, roundTrip)
+		})
+	}
+}
+
+func TestTweet_GeoCashtagFixture(t *testing.T) {
+	tweet := loadTweetFixture(t, filepath.Join("testdata", "tweet_geo_cashtag.json"))
+
+	assert.Equal(t, []Symbol{{Indices: []int{12, 17}, Text: "TWTR"}}, tweet.Entities.Symbol)
+	assert.Equal(t, []int{0, 55}, tweet.DisplayTextRange)
+
+	if assert.NotNil(t, tweet.Coordinates) {
+		assert.Equal(t, "Point", tweet.Coordinates.Type)
+		assert.Equal(t, -104.9847, tweet.Coordinates.Longitude())
+		assert.Equal(t, 39.7392, tweet.Coordinates.Latitude())
+		assert.Equal(t, "39.7392,-104.9847", tweet.Coordinates.String())
+	}
+
+	if assert.NotNil(t, tweet.Place) {
+		assert.Equal(t, "Denver, CO", tweet.Place.FullName)
+		assert.Equal(t, "city", tweet.Place.PlaceType)
+		assert.Equal(t, "US", tweet.Place.CountryCode)
+		if assert.NotNil(t, tweet.Place.BoundingBox) {
+			assert.Equal(t, "Polygon", tweet.Place.BoundingBox.Type)
+			assert.Len(t, tweet.Place.BoundingBox.Coordinates[0], 4)
+			assert.Equal(t, []float64{-105.109815, 39.614151}, tweet.Place.BoundingBox.Coordinates[0][0])
+		}
+	}
+
+	if assert.NotNil(t, tweet.User.Derived) && assert.Len(t, tweet.User.Derived.Locations, 1) {
+		location := tweet.User.Derived.Locations[0]
+		assert.Equal(t, "Denver, Colorado, United States", location.FullName)
+		assert.Equal(t, -104.9847, location.Geo.Longitude())
+	}
+
+	if assert.Len(t, tweet.MatchingRules, 1) {
+		assert.Equal(t, "twitter api", *tweet.MatchingRules[0].Tag)
+		assert.Equal(t, int64(9237843829457829), tweet.MatchingRules[0].ID)
+	}
+
+	output := tweet.TemplateOutput(OutputConfig{})
+	assert.Equal(t, tweet.Entities.Symbol, output.Symbols)
+	assert.Equal(t, tweet.Place, output.Place)
+	assert.Equal(t, tweet.Coordinates, output.Coordinates)
+}
+
+func TestTweet_VideoFixture(t *testing.T) {
+	tweet := loadTweetFixture(t, filepath.Join("testdata", "tweet_video.json"))
+
+	assert.Equal(t, "photo", tweet.Entities.Media[0].Type)
+	media := tweet.AllMedia()
+	if assert.Len(t, media, 1) {
+		assert.Equal(t, "video", media[0].Type)
+		assert.Equal(t, "A developer typing on a laptop", *media[0].ExtAltText)
+		assert.Equal(t, MediaSize{W: 150, H: 150, Resize: "crop"}, media[0].Sizes.Thumb)
+		assert.Equal(t, MediaSize{W: 1280, H: 720, Resize: "fit"}, media[0].Sizes.Large)
+		if assert.NotNil(t, media[0].VideoInfo) {
+			assert.Equal(t, []int{16, 9}, media[0].VideoInfo.AspectRatio)
+			assert.Equal(t, 10704, media[0].VideoInfo.DurationMillis)
+			assert.Len(t, media[0].VideoInfo.Variants, 4)
+			best, ok := media[0].VideoInfo.BestVariant()
+			assert.True(t, ok)
+			assert.Equal(t, 2176000, best.Bitrate)
+		}
+	}
+	assert.Equal(t, media, tweet.TemplateOutput(OutputConfig{}).Media)
+
+	_, ok := (&VideoInfo{}).BestVariant()
+	assert.False(t, ok)
+}
+
+func TestTweet_RetweetFixture(t *testing.T) {
+	tweet := loadTweetFixture(t, filepath.Join("testdata", "retweet.json"))
+
+	if assert.NotNil(t, tweet.ReTweetedStatus) {
+		assert.Equal(t, "TwitterDev", tweet.ReTweetedStatus.User.ScreenName)
+		assert.Equal(t, 402, tweet.ReTweetedStatus.FavoriteCount)
+	}
+	if assert.NotNil(t, tweet.CurrentUserRetweet) {
+		assert.Equal(t, "1237681883401617408", tweet.CurrentUserRetweet.IDStr)
+	}
+	assert.Equal(t, "RT @TwitterDev: Building on Twitter with the new API https://t.co/yt9TnYMYT0",
+		tweet.TweetText(OutputConfig{}))
+}
+
+func TestCoordinates_Empty(t *testing.T) {
+	c := &Coordinates{}
+	assert.Zero(t, c.Longitude())
+	assert.Zero(t, c.Latitude())
+}
+
+func loadTweetFixture(t *testing.T, path string) *Tweet {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweet := &Tweet{}
+	if err := json.Unmarshal(data, tweet); err != nil {
+		t.Fatal(err)
+	}
+	return tweet
+}
+
 func createTweetWithEntities(t *testing.T, hashtags []HashTag, userMentions []UserMention) *Tweet {
 	t.Helper()
 	entities := Entities{}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"sort"
 	"strings"
@@ -23,20 +24,84 @@ var Colors = terminalColors{
 	"white":   "\033[97m",
 }
 
-func init() {
-	// sry winderz
-	if runtime.GOOS == "windows" {
-		Colors = terminalColors{}
+// colorOutput is whether color codes are output, see SetColorMode
+var colorOutput = runtime.GOOS != "windows" // sry winderz
+
+// ColorMode is when to output colors.
+type ColorMode string
+
+const (
+	// ColorAuto outputs colors when stdout is a terminal and NO_COLOR is not set
+	ColorAuto ColorMode = "auto"
+	// ColorAlways outputs colors, even when piped
+	ColorAlways ColorMode = "always"
+	// ColorNever never outputs colors
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode parses one of 'auto', 'always' or 'never'.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(s)); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
 	}
+	return ColorAuto, fmt.Errorf("invalid color mode %q, expected auto, always or never", s)
+}
+
+// SetColorMode sets when colors are output, for both Colors and HighlightEntities.
+func SetColorMode(mode ColorMode) {
+	colorOutput = ColorsEnabled(mode, os.Getenv, IsTerminal(os.Stdout))
+}
+
+// ColorOutput returns whether color codes are currently output.
+func ColorOutput() bool {
+	return colorOutput
 }
 
-// Code returns the terminal color code for the given color name
+// ColorsEnabled decides whether to output colors for the mode, in auto mode colors are disabled
+// when NO_COLOR is set (https://no-color.org), TERM is 'dumb' or the output is not a terminal.
+func ColorsEnabled(mode ColorMode, getenv func(string) string, isTerminal bool) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	switch {
+	case getenv("NO_COLOR") != "", getenv("TERM") == "dumb", !isTerminal, goos == "windows":
+		return false
+	}
+	return true
+}
+
+// IsTerminal returns whether the file is a terminal (character device), rather than a pipe or a file.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Code returns the terminal color code for the given color spec, or an empty string if it is not valid.
+//
+// A spec is a space separated list of attributes and a color, optionally followed by 'on' and a background color, eg:
+// 'red', 'bold underline cyan', '208 on black' or '#ff8700 on #303030'.
+// colors are a name, a 256 color index or '#rrggbb' truecolor, and are downgraded to the nearest
+// color the terminal supports, according to TerminalColorDepth.
 func (c terminalColors) Code(color string) string {
-	lcolor := strings.ToLower(color)
+	if !colorOutput {
+		return ""
+	}
+	lcolor := strings.ToLower(strings.TrimSpace(color))
 	if val, ok := c[lcolor]; ok {
 		return val
 	}
-	return ""
+	params, ok := parseColorSpec(lcolor, TerminalColorDepth)
+	if !ok || len(params) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
 }
 
 // Colorize returns the given string wrapped in the appropriately named color code and the 'reset' color code.
@@ -61,22 +126,34 @@ func (l HighlightEntityList) Swap(i, j int) {
 	l[i], l[j] = l[j], l[i]
 }
 func (l HighlightEntityList) Less(i, j int) bool {
+	if l[i].StartIdx == l[j].StartIdx {
+		return l[i].EndIdx > l[j].EndIdx // longest first
+	}
 	return l[i].StartIdx < l[j].StartIdx
 }
 
-// HighlightEntities attempts to colorize the given text, with the given HighlightEntityList
+// HighlightEntities attempts to colorize the given text, with the given HighlightEntityList.
+// Indices are utf-16 code units into the (html unescaped) text, as twitter counts them.
+// Entities that are empty, out of range, split a character or overlap an earlier entity are skipped,
+// when entities start at the same index the longest one is highlighted.
+// The text is returned as is when color output is disabled.
 func HighlightEntities(text string, hlist HighlightEntityList) string {
-	sort.Sort(hlist)
-	rtext := []rune(text)
-	resultText := ""
+	if !colorOutput {
+		return text
+	}
+	sort.Stable(hlist)
+	utext := NewUTF16Text(text)
+	var sb strings.Builder
 	curIdx := 0
 	for _, entry := range hlist {
-		if entry.StartIdx >= curIdx && entry.EndIdx <= len(text) && entry.StartIdx <= entry.EndIdx {
-			resultText += string(rtext[curIdx:entry.StartIdx])
-			resultText += Colors.Colorize(entry.Color, string(rtext[entry.StartIdx:entry.EndIdx]))
-			curIdx = entry.EndIdx
+		if entry.StartIdx < curIdx || entry.StartIdx >= entry.EndIdx ||
+			!utext.IsBoundary(entry.StartIdx) || !utext.IsBoundary(entry.EndIdx) {
+			continue
 		}
+		sb.WriteString(utext.Slice(curIdx, entry.StartIdx))
+		sb.WriteString(Colors.Colorize(entry.Color, utext.Slice(entry.StartIdx, entry.EndIdx)))
+		curIdx = entry.EndIdx
 	}
-	resultText += string(rtext[curIdx:])
-	return resultText
+	sb.WriteString(utext.Slice(curIdx, utext.Len()))
+	return sb.String()
 }
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"sort"
 	"testing"
@@ -134,6 +135,99 @@ func TestHighlightEntities(t *testing.T) {
 	}
 }
 
+func TestHighlightEntities_Unicode(t *testing.T) {
+	red := func(s string) string { return Colors.Colorize("red", s) }
+	blue := func(s string) string { return Colors.Colorize("blue", s) }
+	tests := []struct {
+		name     string
+		text     string
+		entities HighlightEntityList
+		want     string
+	}{
+		{
+			"emoji counts as two utf-16 units",
+			"👍 #go rocks",
+			[]HighlightEntity{{StartIdx: 3, EndIdx: 6, Color: "red"}},
+			"👍 " + red("#go") + " rocks",
+		},
+		{
+			"emoji inside entity",
+			"hi @you👋 there",
+			[]HighlightEntity{{StartIdx: 3, EndIdx: 9, Color: "red"}},
+			"hi " + red("@you👋") + " there",
+		},
+		{
+			"multiple emoji before entities",
+			"🎉🎉 @user and #tag",
+			[]HighlightEntity{
+				{StartIdx: 5, EndIdx: 10, Color: "red"},
+				{StartIdx: 15, EndIdx: 19, Color: "blue"},
+			},
+			"🎉🎉 " + red("@user") + " and " + blue("#tag"),
+		},
+		{
+			"cjk",
+			"日本語のツイート #タグ です",
+			[]HighlightEntity{{StartIdx: 9, EndIdx: 12, Color: "red"}},
+			"日本語のツイート " + red("#タグ") + " です",
+		},
+		{
+			"entity at the end of multi byte text",
+			"カフェ☕ #coffee",
+			[]HighlightEntity{{StartIdx: 5, EndIdx: 12, Color: "red"}},
+			"カフェ☕ " + red("#coffee"),
+		},
+		{
+			"unescaped html entity",
+			"Tom & Jerry #cartoon",
+			[]HighlightEntity{{StartIdx: 12, EndIdx: 20, Color: "red"}},
+			"Tom & Jerry " + red("#cartoon"),
+		},
+		{
+			"splits a surrogate pair",
+			"👍 #go",
+			[]HighlightEntity{{StartIdx: 1, EndIdx: 6, Color: "red"}},
+			"👍 #go",
+		},
+		{
+			"end past utf-16 length",
+			"👍 #go",
+			[]HighlightEntity{{StartIdx: 3, EndIdx: 8, Color: "red"}},
+			"👍 #go",
+		},
+		{
+			"overlapping entities keep the first",
+			"test one two three",
+			[]HighlightEntity{
+				{StartIdx: 5, EndIdx: 12, Color: "red"},
+				{StartIdx: 9, EndIdx: 18, Color: "blue"},
+			},
+			"test " + red("one two") + " three",
+		},
+		{
+			"same start keeps the longest",
+			"test one two three",
+			[]HighlightEntity{
+				{StartIdx: 5, EndIdx: 8, Color: "blue"},
+				{StartIdx: 5, EndIdx: 12, Color: "red"},
+			},
+			"test " + red("one two") + " three",
+		},
+		{
+			"empty entity",
+			"test one two three",
+			[]HighlightEntity{{StartIdx: 5, EndIdx: 5, Color: "red"}},
+			"test one two three",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := HighlightEntities(test.text, test.entities)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
 func TestHighlightEntityList_Sortable(t *testing.T) {
 	first := HighlightEntity{StartIdx: 0}
 	second := HighlightEntity{StartIdx: 5}
@@ -152,3 +246,95 @@ func TestHighlightEntityList_Sortable(t *testing.T) {
 	sort.Sort(reversed)
 	assert.Equal(t, sortedExpectation, reversed)
 }
+
+func withColorOutput(t *testing.T, enabled bool) {
+	outputSave := colorOutput
+	t.Cleanup(func() { colorOutput = outputSave })
+	colorOutput = enabled
+}
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ColorMode
+		err   bool
+	}{
+		{"auto", ColorAuto, false},
+		{"always", ColorAlways, false},
+		{"never", ColorNever, false},
+		{"NEVER", ColorNever, false},
+		{"", ColorAuto, true},
+		{"sometimes", ColorAuto, true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseColorMode(test.input)
+			assert.Equal(t, test.want, got)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestColorsEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       ColorMode
+		env        map[string]string
+		isTerminal bool
+		goos       string
+		want       bool
+	}{
+		{"auto terminal", ColorAuto, nil, true, "linux", true},
+		{"auto piped", ColorAuto, nil, false, "linux", false},
+		{"auto no color", ColorAuto, map[string]string{"NO_COLOR": "1"}, true, "linux", false},
+		{"auto empty no color", ColorAuto, map[string]string{"NO_COLOR": ""}, true, "linux", true},
+		{"auto dumb terminal", ColorAuto, map[string]string{"TERM": "dumb"}, true, "linux", false},
+		{"auto windows", ColorAuto, nil, true, "windows", false},
+		{"always piped", ColorAlways, map[string]string{"NO_COLOR": "1"}, false, "windows", true},
+		{"never terminal", ColorNever, nil, true, "linux", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			goosSave := goos
+			defer func() { goos = goosSave }()
+			goos = test.goos
+			getenv := func(key string) string { return test.env[key] }
+			assert.Equal(t, test.want, ColorsEnabled(test.mode, getenv, test.isTerminal))
+		})
+	}
+}
+
+func TestSetColorMode(t *testing.T) {
+	withColorOutput(t, true)
+	SetColorMode(ColorNever)
+	assert.False(t, ColorOutput())
+	SetColorMode(ColorAlways)
+	assert.True(t, ColorOutput())
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.False(t, IsTerminal(f), "a regular file is not a terminal")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	assert.False(t, IsTerminal(w), "a pipe is not a terminal")
+}
+
+func TestHighlightEntities_ColorOutputDisabled(t *testing.T) {
+	withColorOutput(t, false)
+	text := "test #one two"
+	got := HighlightEntities(text, []HighlightEntity{{StartIdx: 5, EndIdx: 9, Color: "red"}})
+	assert.Equal(t, text, got)
+}
@@ -0,0 +1,258 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is the range of colors a terminal can display.
+type ColorDepth int
+
+const (
+	// ColorDepthBasic is the 8 standard ansi colors
+	ColorDepthBasic ColorDepth = iota
+	// ColorDepth256 is the xterm 256 color palette
+	ColorDepth256
+	// ColorDepthTrue is 24 bit rgb color
+	ColorDepthTrue
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepth256:
+		return "256"
+	case ColorDepthTrue:
+		return "truecolor"
+	}
+	return "basic"
+}
+
+// TerminalColorDepth is the color depth of the terminal, colors deeper than this are downgraded.
+var TerminalColorDepth = DetectColorDepth(os.Getenv)
+
+// DetectColorDepth determines the color depth of the terminal from the COLORTERM and TERM environment variables.
+func DetectColorDepth(getenv func(string) string) ColorDepth {
+	colorTerm := strings.ToLower(getenv("COLORTERM"))
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case colorTerm == "truecolor" || colorTerm == "24bit",
+		strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"), strings.Contains(term, "direct"):
+		return ColorDepthTrue
+	case strings.Contains(term, "256"):
+		return ColorDepth256
+	}
+	return ColorDepthBasic
+}
+
+var colorAttributes = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+}
+
+// basicColor is one of the standard ansi colors, with its approximate rgb value for downgrading.
+type basicColor struct {
+	name    string
+	code    int // foreground code, background is +10
+	r, g, b uint8
+}
+
+var basicColors = []basicColor{
+	{"black", 30, 0, 0, 0},
+	{"red", 31, 205, 0, 0},
+	{"green", 32, 0, 205, 0},
+	{"yellow", 33, 205, 205, 0},
+	{"blue", 34, 0, 0, 238},
+	{"magenta", 35, 205, 0, 205},
+	{"cyan", 36, 0, 205, 205},
+	{"gray", 37, 229, 229, 229},
+	{"white", 97, 255, 255, 255},
+}
+
+// parseColorSpec returns the sgr parameters for the given lowercase color spec.
+func parseColorSpec(spec string, depth ColorDepth) ([]string, bool) {
+	var params []string
+	fields := strings.Fields(spec)
+	colors := 0
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if attr, ok := colorAttributes[field]; ok {
+			params = append(params, attr)
+			continue
+		}
+		background := false
+		if field == "on" {
+			if i+1 >= len(fields) {
+				return nil, false
+			}
+			i++
+			field, background = fields[i], true
+		} else if colors > 0 {
+			return nil, false // a second foreground color
+		}
+		colorParams, ok := parseColor(field, background, depth)
+		if !ok {
+			return nil, false
+		}
+		params = append(params, colorParams...)
+		colors++
+	}
+	return params, true
+}
+
+// parseColor returns the sgr parameters for a single color, by name, 256 color index or '#rrggbb'.
+func parseColor(color string, background bool, depth ColorDepth) ([]string, bool) {
+	kind := "38"
+	if background {
+		kind = "48"
+	}
+	if bc, ok := findBasicColor(color); ok {
+		return []string{bc.param(background)}, true
+	}
+	if idx, err := strconv.Atoi(color); err == nil {
+		if idx < 0 || idx > 255 {
+			return nil, false
+		}
+		if depth == ColorDepthBasic {
+			r, g, b := xterm256RGB(idx)
+			return []string{nearestBasicColor(r, g, b).param(background)}, true
+		}
+		return []string{kind, "5", strconv.Itoa(idx)}, true
+	}
+	r, g, b, ok := parseHexColor(color)
+	if !ok {
+		return nil, false
+	}
+	switch depth {
+	case ColorDepthTrue:
+		return []string{kind, "2", strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b))}, true
+	case ColorDepth256:
+		return []string{kind, "5", strconv.Itoa(nearestXterm256(r, g, b))}, true
+	}
+	return []string{nearestBasicColor(r, g, b).param(background)}, true
+}
+
+func (bc basicColor) param(background bool) string {
+	if background {
+		return strconv.Itoa(bc.code + 10)
+	}
+	return strconv.Itoa(bc.code)
+}
+
+func findBasicColor(name string) (basicColor, bool) {
+	for _, bc := range basicColors {
+		if bc.name == name {
+			return bc, true
+		}
+	}
+	return basicColor{}, false
+}
+
+// parseHexColor parses '#rrggbb' or the short form '#rgb'
+func parseHexColor(color string) (r, g, b uint8, ok bool) {
+	if !strings.HasPrefix(color, "#") {
+		return 0, 0, 0, false
+	}
+	hex := color[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// the xterm palette, the first 16 colors are the standard and bright ansi colors
+var xterm16 = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// the levels of each component in the 6x6x6 color cube
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+func xterm256RGB(idx int) (r, g, b uint8) {
+	switch {
+	case idx < 16:
+		c := xterm16[idx]
+		return c[0], c[1], c[2]
+	case idx < 232:
+		idx -= 16
+		return cubeLevels[idx/36], cubeLevels[idx/6%6], cubeLevels[idx%6]
+	}
+	gray := uint8(8 + 10*(idx-232))
+	return gray, gray, gray
+}
+
+// nearestXterm256 returns the closest color from the cube or grayscale ramp, the first 16 are skipped
+// as terminals often customize them.
+func nearestXterm256(r, g, b uint8) int {
+	cube := 16 + 36*nearestCubeLevel(r) + 6*nearestCubeLevel(g) + nearestCubeLevel(b)
+	avg := (int(r) + int(g) + int(b)) / 3
+	grayIdx := (avg - 8 + 5) / 10
+	if grayIdx < 0 {
+		grayIdx = 0
+	} else if grayIdx > 23 {
+		grayIdx = 23
+	}
+	gray := 232 + grayIdx
+	cr, cg, cb := xterm256RGB(cube)
+	gr, gg, gb := xterm256RGB(gray)
+	if colorDistance(r, g, b, gr, gg, gb) < colorDistance(r, g, b, cr, cg, cb) {
+		return gray
+	}
+	return cube
+}
+
+func nearestCubeLevel(v uint8) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if absDiff(v, level) < absDiff(v, cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func nearestBasicColor(r, g, b uint8) basicColor {
+	best := basicColors[0]
+	bestDist := colorDistance(r, g, b, best.r, best.g, best.b)
+	for _, bc := range basicColors[1:] {
+		if d := colorDistance(r, g, b, bc.r, bc.g, bc.b); d < bestDist {
+			best, bestDist = bc, d
+		}
+	}
+	return best
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := absDiff(r1, r2), absDiff(g1, g2), absDiff(b1, b2)
+	return dr*dr + dg*dg + db*db
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// ValidColor returns an error if the color spec is not valid.
+func ValidColor(spec string) error {
+	lspec := strings.ToLower(strings.TrimSpace(spec))
+	if lspec == "" || lspec == "reset" {
+		return nil
+	}
+	if _, ok := parseColorSpec(lspec, ColorDepthTrue); !ok {
+		return fmt.Errorf("invalid color %q", spec)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package util
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withColorDepth(t *testing.T, depth ColorDepth) {
+	depthSave := TerminalColorDepth
+	t.Cleanup(func() { TerminalColorDepth = depthSave })
+	TerminalColorDepth = depth
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		name      string
+		colorTerm string
+		term      string
+		want      ColorDepth
+	}{
+		{"nothing", "", "", ColorDepthBasic},
+		{"xterm", "", "xterm", ColorDepthBasic},
+		{"xterm 256", "", "xterm-256color", ColorDepth256},
+		{"screen 256", "", "screen-256color", ColorDepth256},
+		{"colorterm truecolor", "truecolor", "xterm-256color", ColorDepthTrue},
+		{"colorterm 24bit", "24bit", "xterm", ColorDepthTrue},
+		{"colorterm case", "TrueColor", "", ColorDepthTrue},
+		{"term direct", "", "xterm-direct", ColorDepthTrue},
+		{"colorterm other", "yes", "xterm", ColorDepthBasic},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := map[string]string{"COLORTERM": test.colorTerm, "TERM": test.term}
+			got := DetectColorDepth(func(key string) string { return env[key] })
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestColorDepth_String(t *testing.T) {
+	assert.Equal(t, "basic", ColorDepthBasic.String())
+	assert.Equal(t, "256", ColorDepth256.String())
+	assert.Equal(t, "truecolor", ColorDepthTrue.String())
+}
+
+func TestTerminalColors_Code_Spec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("colors are disabled on windows")
+	}
+	tests := []struct {
+		name  string
+		spec  string
+		depth ColorDepth
+		want  string
+	}{
+		{"attribute", "bold", ColorDepthBasic, "\033[1m"},
+		{"attributes and color", "bold underline red", ColorDepthBasic, "\033[1;4;31m"},
+		{"italic dim", "italic dim cyan", ColorDepthBasic, "\033[3;2;36m"},
+		{"case and spaces", "  Bold  RED ", ColorDepthBasic, "\033[1;31m"},
+		{"background", "white on blue", ColorDepthBasic, "\033[97;44m"},
+		{"background only", "on red", ColorDepthBasic, "\033[41m"},
+		{"256 index", "208", ColorDepth256, "\033[38;5;208m"},
+		{"256 index truecolor", "208", ColorDepthTrue, "\033[38;5;208m"},
+		{"256 background", "bold 15 on 236", ColorDepth256, "\033[1;38;5;15;48;5;236m"},
+		{"256 downgraded", "196", ColorDepthBasic, "\033[31m"},
+		{"256 gray downgraded", "250", ColorDepthBasic, "\033[37m"},
+		{"256 standard downgraded", "4", ColorDepthBasic, "\033[34m"},
+		{"truecolor", "#ff8700", ColorDepthTrue, "\033[38;2;255;135;0m"},
+		{"truecolor short", "#f80", ColorDepthTrue, "\033[38;2;255;136;0m"},
+		{"truecolor background", "#000000 on #ffffff", ColorDepthTrue, "\033[38;2;0;0;0;48;2;255;255;255m"},
+		{"truecolor to 256", "#ff8700", ColorDepth256, "\033[38;5;208m"},
+		{"truecolor gray to 256", "#303030", ColorDepth256, "\033[38;5;236m"},
+		{"truecolor to basic", "#1030e0", ColorDepthBasic, "\033[34m"},
+		{"truecolor blueish to basic", "#268bd2", ColorDepthBasic, "\033[36m"},
+		{"truecolor background to basic", "on #dc322f", ColorDepthBasic, "\033[41m"},
+		{"truecolor near white to basic", "#fafafa", ColorDepthBasic, "\033[97m"},
+		{"unknown name", "purple", ColorDepthTrue, ""},
+		{"two colors", "red blue", ColorDepthTrue, ""},
+		{"dangling on", "red on", ColorDepthTrue, ""},
+		{"index out of range", "256", ColorDepthTrue, ""},
+		{"negative index", "-1", ColorDepthTrue, ""},
+		{"bad hex", "#ggg", ColorDepthTrue, ""},
+		{"hex length", "#ffff", ColorDepthTrue, ""},
+		{"empty", "", ColorDepthTrue, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withColorDepth(t, test.depth)
+			assert.Equal(t, test.want, Colors.Code(test.spec))
+		})
+	}
+}
+
+func TestTerminalColors_Code_Disabled(t *testing.T) {
+	withColorOutput(t, false)
+	assert.Equal(t, "", Colors.Code("bold #ff0000"))
+	assert.Equal(t, "", Colors.Code("red"))
+	assert.Equal(t, "text", Colors.Colorize("red", "text"))
+}
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		idx     int
+		r, g, b uint8
+	}{
+		{0, 0, 0, 0},
+		{9, 255, 0, 0},
+		{16, 0, 0, 0},
+		{21, 0, 0, 255},
+		{196, 255, 0, 0},
+		{231, 255, 255, 255},
+		{232, 8, 8, 8},
+		{255, 238, 238, 238},
+	}
+	for _, test := range tests {
+		r, g, b := xterm256RGB(test.idx)
+		assert.Equal(t, [3]uint8{test.r, test.g, test.b}, [3]uint8{r, g, b}, "index %d", test.idx)
+	}
+	// every color in the cube and ramp maps back to itself
+	for idx := 16; idx < 256; idx++ {
+		r, g, b := xterm256RGB(idx)
+		assert.Equal(t, idx, nearestXterm256(r, g, b))
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	for _, spec := range []string{"", "reset", "red", "bold", "208", "#fff", "bold #ff8700 on 236", "White On Blue"} {
+		assert.NoError(t, ValidColor(spec), spec)
+	}
+	for _, spec := range []string{"purple", "red blue", "on", "300", "#12345"} {
+		assert.Error(t, ValidColor(spec), spec)
+	}
+}
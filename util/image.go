@@ -0,0 +1,284 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strings"
+)
+
+// ImageProtocol is how images are drawn in the terminal.
+type ImageProtocol string
+
+const (
+	ImageProtocolAuto      ImageProtocol = "auto"
+	ImageProtocolKitty     ImageProtocol = "kitty"
+	ImageProtocolITerm2    ImageProtocol = "iterm2"
+	ImageProtocolSixel     ImageProtocol = "sixel"
+	ImageProtocolHalfBlock ImageProtocol = "halfblock"
+)
+
+var imageProtocols = []ImageProtocol{
+	ImageProtocolAuto, ImageProtocolKitty, ImageProtocolITerm2, ImageProtocolSixel, ImageProtocolHalfBlock,
+}
+
+// ParseImageProtocol parses an image protocol name, an empty name is auto.
+func ParseImageProtocol(s string) (ImageProtocol, error) {
+	if s == "" {
+		return ImageProtocolAuto, nil
+	}
+	for _, p := range imageProtocols {
+		if strings.EqualFold(s, string(p)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid image protocol %q, expected one of auto, kitty, iterm2, sixel or halfblock", s)
+}
+
+// DetectImageProtocol returns the image protocol the terminal supports, based on the environment,
+// falling back to half blocks which only need color support.
+func DetectImageProtocol(getenv func(string) string) ImageProtocol {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", program == "ghostty":
+		return ImageProtocolKitty
+	case program == "iTerm.app", program == "WezTerm", getenv("LC_TERMINAL") == "iTerm2":
+		return ImageProtocolITerm2
+	case strings.Contains(term, "sixel"), term == "mlterm", strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "yaft"):
+		return ImageProtocolSixel
+	}
+	return ImageProtocolHalfBlock
+}
+
+// the size in pixels assumed for a terminal cell, when sizing images in pixels
+const (
+	cellWidth  = 8
+	cellHeight = 16
+)
+
+// RenderImage returns the output that draws the image in the terminal with the given protocol,
+// scaled to fit in cols x rows cells keeping its aspect ratio, followed by a new line.
+func RenderImage(img image.Image, protocol ImageProtocol, cols, rows int) (string, error) {
+	if cols <= 0 || rows <= 0 {
+		return "", fmt.Errorf("invalid image size %dx%d", cols, rows)
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return "", fmt.Errorf("empty image")
+	}
+	switch protocol {
+	case ImageProtocolKitty, ImageProtocolITerm2:
+		w, h := fitSize(b.Dx(), b.Dy(), cols*cellWidth, rows*cellHeight)
+		data, err := encodePNG(scaleImage(img, w, h))
+		if err != nil {
+			return "", err
+		}
+		cols, rows = ceilDiv(w, cellWidth), ceilDiv(h, cellHeight)
+		if protocol == ImageProtocolKitty {
+			return kittyImage(data, cols, rows), nil
+		}
+		return iterm2Image(data, cols, rows), nil
+	case ImageProtocolSixel:
+		w, h := fitSize(b.Dx(), b.Dy(), cols*cellWidth, rows*cellHeight)
+		return sixelImage(scaleImage(img, w, h)), nil
+	case ImageProtocolHalfBlock:
+		// each cell is 2 pixels tall, a cell is about twice as tall as it is wide, so pixels are square
+		w, h := fitSize(b.Dx(), b.Dy(), cols, rows*2)
+		return halfBlockImage(scaleImage(img, w, h)), nil
+	}
+	return "", fmt.Errorf("unsupported image protocol %q", protocol)
+}
+
+// Thumbnail scales the image down to the largest size RenderImage draws it at in cols x rows cells.
+func Thumbnail(img image.Image, cols, rows int) image.Image {
+	b := img.Bounds()
+	w, h := fitSize(b.Dx(), b.Dy(), cols*cellWidth, rows*cellHeight)
+	if w == b.Dx() && h == b.Dy() {
+		return img
+	}
+	return scaleImage(img, w, h)
+}
+
+// fitSize returns the width and height scaled to fit in maxW x maxH, keeping the aspect ratio,
+// images are only scaled down.
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	if w*maxH > h*maxW { // wider than the box
+		return maxW, maxInt(1, h*maxW/w)
+	}
+	return maxInt(1, w*maxH/h), maxH
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// scaleImage resizes the image to w x h, each pixel is the average of the pixels it covers.
+func scaleImage(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+ceilDiv((y+1)*b.Dy(), h)
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+ceilDiv((x+1)*b.Dx(), w)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+pr, g+pg, bl+pb, a+pa, n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// the kitty graphics protocol limits each escape sequence to this much base64 data
+const kittyChunkSize = 4096
+
+// kittyImage draws a png with the kitty graphics protocol, see https://sw.kovidgoyal.net/kitty/graphics-protocol/
+func kittyImage(data []byte, cols, rows int) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var sb strings.Builder
+	for i := 0; i < len(encoded); i += kittyChunkSize {
+		end := i + kittyChunkSize
+		more := 1
+		if end >= len(encoded) {
+			end, more = len(encoded), 0
+		}
+		if i == 0 {
+			sb.WriteString(fmt.Sprintf("\033_Gf=100,a=T,c=%d,r=%d,m=%d;", cols, rows, more))
+		} else {
+			sb.WriteString(fmt.Sprintf("\033_Gm=%d;", more))
+		}
+		sb.WriteString(encoded[i:end])
+		sb.WriteString("\033\\")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// iterm2Image draws an image with the iTerm2 inline images protocol, see https://iterm2.com/documentation-images.html
+func iterm2Image(data []byte, cols, rows int) string {
+	return fmt.Sprintf("\033]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a\n",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixelImage draws an image as sixels, reduced to the web safe palette.
+func sixelImage(img image.Image) string {
+	b := img.Bounds()
+	pal := image.NewPaletted(b, palette.WebSafe)
+	draw.FloydSteinberg.Draw(pal, b, img, b.Min)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\033Pq\"1;1;%d;%d", b.Dx(), b.Dy()))
+	// only the colors used are defined, with rgb in percent
+	defined := make(map[uint8]bool)
+	for _, i := range pal.Pix {
+		defined[i] = true
+	}
+	for i, c := range pal.Palette {
+		if !defined[uint8(i)] {
+			continue
+		}
+		r, g, bl, _ := c.RGBA()
+		sb.WriteString(fmt.Sprintf("#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff))
+	}
+	// each sixel is a column of 6 pixels, drawn one color at a time
+	for y := b.Min.Y; y < b.Max.Y; y += 6 {
+		used := make(map[uint8]bool)
+		for dy := 0; dy < 6 && y+dy < b.Max.Y; dy++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				used[pal.ColorIndexAt(x, y+dy)] = true
+			}
+		}
+		colors := make([]int, 0, len(used))
+		for c := range used {
+			colors = append(colors, int(c))
+		}
+		sort.Ints(colors)
+		for _, c := range colors {
+			sb.WriteString(fmt.Sprintf("#%d", c))
+			var last byte
+			run := 0
+			for x := b.Min.X; x < b.Max.X; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && y+dy < b.Max.Y; dy++ {
+					if int(pal.ColorIndexAt(x, y+dy)) == c {
+						bits |= 1 << dy
+					}
+				}
+				ch := byte('?' + bits)
+				if run > 0 && ch != last {
+					writeSixelRun(&sb, last, run)
+					run = 0
+				}
+				last = ch
+				run++
+			}
+			writeSixelRun(&sb, last, run)
+			sb.WriteString("$")
+		}
+		sb.WriteString("-")
+	}
+	sb.WriteString("\033\\\n")
+	return sb.String()
+}
+
+// writeSixelRun writes the sixel n times, run length encoded when shorter.
+func writeSixelRun(sb *strings.Builder, ch byte, n int) {
+	if n > 3 {
+		sb.WriteString(fmt.Sprintf("!%d%c", n, ch))
+		return
+	}
+	sb.WriteString(strings.Repeat(string(ch), n))
+}
+
+// halfBlockImage draws an image with upper half block characters, the foreground colors
+// the top pixel and the background the bottom pixel of each cell.
+func halfBlockImage(img image.Image) string {
+	b := img.Bounds()
+	var sb strings.Builder
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			spec := hexColor(img.At(x, y))
+			if y+1 < b.Max.Y {
+				spec += " on " + hexColor(img.At(x, y+1))
+			}
+			sb.WriteString(Colors.Code(spec))
+			sb.WriteString("▀")
+		}
+		sb.WriteString(Colors.Code("reset"))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,200 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testImage returns an image with a red top half and a blue bottom half.
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if y >= h/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestParseImageProtocol(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ImageProtocol
+		wantErr bool
+	}{
+		{"", ImageProtocolAuto, false},
+		{"auto", ImageProtocolAuto, false},
+		{"kitty", ImageProtocolKitty, false},
+		{"iTerm2", ImageProtocolITerm2, false},
+		{"sixel", ImageProtocolSixel, false},
+		{"halfblock", ImageProtocolHalfBlock, false},
+		{"ascii", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseImageProtocol(test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestDetectImageProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ImageProtocol
+	}{
+		{"kitty window", map[string]string{"KITTY_WINDOW_ID": "1", "TERM": "xterm-256color"}, ImageProtocolKitty},
+		{"kitty term", map[string]string{"TERM": "xterm-kitty"}, ImageProtocolKitty},
+		{"ghostty", map[string]string{"TERM_PROGRAM": "ghostty"}, ImageProtocolKitty},
+		{"iterm", map[string]string{"TERM_PROGRAM": "iTerm.app"}, ImageProtocolITerm2},
+		{"iterm over ssh", map[string]string{"LC_TERMINAL": "iTerm2"}, ImageProtocolITerm2},
+		{"wezterm", map[string]string{"TERM_PROGRAM": "WezTerm"}, ImageProtocolITerm2},
+		{"mlterm", map[string]string{"TERM": "mlterm"}, ImageProtocolSixel},
+		{"foot", map[string]string{"TERM": "foot-extra"}, ImageProtocolSixel},
+		{"sixel term", map[string]string{"TERM": "xterm-sixel"}, ImageProtocolSixel},
+		{"other", map[string]string{"TERM": "xterm-256color"}, ImageProtocolHalfBlock},
+		{"empty", map[string]string{}, ImageProtocolHalfBlock},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getenv := func(key string) string { return test.env[key] }
+			assert.Equal(t, test.want, DetectImageProtocol(getenv))
+		})
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		maxW, maxH   int
+		wantW, wantH int
+	}{
+		{"fits", 10, 10, 20, 20, 10, 10},
+		{"wide", 200, 100, 40, 40, 40, 20},
+		{"tall", 100, 200, 40, 40, 20, 40},
+		{"exact", 40, 20, 40, 20, 40, 20},
+		{"thin", 1000, 1, 10, 10, 10, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, h := fitSize(test.w, test.h, test.maxW, test.maxH)
+			assert.Equal(t, test.wantW, w)
+			assert.Equal(t, test.wantH, h)
+		})
+	}
+}
+
+func TestScaleImage(t *testing.T) {
+	img := scaleImage(testImage(8, 8), 2, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), img.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.RGBAAt(1, 0))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, img.RGBAAt(1, 1))
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		w, h       int
+		cols, rows int
+		want       image.Rectangle
+	}{
+		{"scaled to the cells", 800, 400, 10, 5, image.Rect(0, 0, 80, 40)},
+		{"small images are kept", 8, 8, 10, 5, image.Rect(0, 0, 8, 8)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Thumbnail(testImage(test.w, test.h), test.cols, test.rows).Bounds())
+		})
+	}
+}
+
+func TestRenderImage_HalfBlock(t *testing.T) {
+	withColorOutput(t, true)
+	withColorDepth(t, ColorDepthTrue)
+
+	out, err := RenderImage(testImage(20, 20), ImageProtocolHalfBlock, 2, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cell := "\033[38;2;255;0;0;48;2;0;0;255m▀"
+	assert.Equal(t, cell+cell+"\033[0m\n", out)
+}
+
+func TestRenderImage_Kitty(t *testing.T) {
+	out, err := RenderImage(testImage(160, 80), ImageProtocolKitty, 10, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.True(t, strings.HasPrefix(out, "\033_Gf=100,a=T,c=10,r=3,m=0;"), out) {
+		return
+	}
+	assert.True(t, strings.HasSuffix(out, "\033\\\n"))
+
+	payload := strings.TrimSuffix(strings.TrimPrefix(out, "\033_Gf=100,a=T,c=10,r=3,m=0;"), "\033\\\n")
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, image.Rect(0, 0, 80, 40), img.Bounds())
+}
+
+func TestKittyImage_Chunks(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, kittyChunkSize) // encodes to more than one chunk
+	out := kittyImage(data, 4, 2)
+	chunks := strings.Split(strings.TrimSuffix(out, "\n"), "\033\\")
+	if !assert.Len(t, chunks, 3) { // 2 chunks and the empty string after the last terminator
+		return
+	}
+	assert.True(t, strings.HasPrefix(chunks[0], "\033_Gf=100,a=T,c=4,r=2,m=1;"))
+	assert.True(t, strings.HasPrefix(chunks[1], "\033_Gm=0;"))
+}
+
+func TestRenderImage_ITerm2(t *testing.T) {
+	out, err := RenderImage(testImage(16, 16), ImageProtocolITerm2, 10, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(out, "\033]1337;File=inline=1;size="), out)
+	assert.Contains(t, out, ";width=2;height=1;preserveAspectRatio=1:")
+	assert.True(t, strings.HasSuffix(out, "\a\n"))
+}
+
+func TestRenderImage_Sixel(t *testing.T) {
+	out, err := RenderImage(testImage(8, 12), ImageProtocolSixel, 10, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// blue and red from the web safe palette, then the red rows and the blue rows, each a band of 6 pixels
+	assert.Equal(t, "\033Pq\"1;1;8;12#5;2;0;0;100#180;2;100;0;0#180!8~$-#5!8~$-\033\\\n", out)
+}
+
+func TestRenderImage_Errors(t *testing.T) {
+	_, err := RenderImage(testImage(4, 4), ImageProtocolHalfBlock, 0, 10)
+	assert.Error(t, err)
+	_, err = RenderImage(image.NewRGBA(image.Rect(0, 0, 0, 0)), ImageProtocolHalfBlock, 10, 10)
+	assert.Error(t, err)
+	_, err = RenderImage(testImage(4, 4), ImageProtocolAuto, 10, 10)
+	assert.Error(t, err)
+}
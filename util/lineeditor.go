@@ -0,0 +1,521 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Completer returns the candidates that complete the word at the end of line, the text before the cursor,
+// and the offset in line where that word starts.
+type Completer func(line string) (start int, candidates []string)
+
+// the keys read from escape sequences
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyEscape
+	keyUnknown
+)
+
+// the control keys
+const (
+	ctrlA        = 0x01
+	ctrlB        = 0x02
+	ctrlD        = 0x04
+	ctrlE        = 0x05
+	ctrlF        = 0x06
+	ctrlG        = 0x07
+	ctrlH        = 0x08
+	keyTab       = 0x09
+	ctrlJ        = 0x0a
+	ctrlK        = 0x0b
+	ctrlL        = 0x0c
+	keyEnter     = 0x0d
+	ctrlN        = 0x0e
+	ctrlP        = 0x10
+	ctrlR        = 0x12
+	ctrlU        = 0x15
+	ctrlW        = 0x17
+	keyEsc       = 0x1b
+	keyBackspace = 0x7f
+)
+
+// LineEditor reads lines from a terminal in raw input mode, see RawInput, with cursor movement,
+// recall of the line history, ctrl-r reverse search and tab completion.
+// text printed while a line is edited is written above it, and the line is redrawn.
+type LineEditor struct {
+	Complete Completer
+
+	in      *bufio.Reader
+	out     io.Writer
+	history *LineHistory
+
+	lock      sync.Mutex // guards the state below, a line is edited while other output is printed
+	prompt    string
+	buf       []rune
+	pos       int
+	reading   bool
+	cursorRow int // the row of the cursor in the last render, from the prompt
+	linesRead bool
+	lines     []string    // the history, read when it is first used for the line
+	lineIdx   int         // the recalled line, len(lines) for the line being typed
+	draft     []rune      // the line being typed, kept while lines are recalled
+	search    *lineSearch // set while searching the history
+}
+
+// lineSearch is a ctrl-r reverse incremental search of the history.
+type lineSearch struct {
+	query    []rune
+	match    int // index in lines of the match, -1 for none
+	failed   bool
+	saved    []rune // the line before the search, restored when it is cancelled
+	savedPos int
+}
+
+// NewLineEditor creates a line editor that reads keys from in and writes to out,
+// entered lines are recalled from the history.
+func NewLineEditor(in io.Reader, out io.Writer, history *LineHistory) *LineEditor {
+	if history == nil {
+		history = NewLineHistory("", 0)
+	}
+	return &LineEditor{in: bufio.NewReader(in), out: out, history: history}
+}
+
+// SetPrompt changes the prompt, redrawing the line being edited.
+func (e *LineEditor) SetPrompt(prompt string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.prompt = prompt
+	if e.reading {
+		e.render()
+	}
+}
+
+// Print writes the text above the line being edited, then redraws the line.
+func (e *LineEditor) Print(s string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if !e.reading {
+		_, _ = io.WriteString(e.out, s)
+		return
+	}
+	var sb strings.Builder
+	e.clear(&sb)
+	sb.WriteString(s)
+	if !strings.HasSuffix(s, "\n") {
+		sb.WriteString("\n")
+	}
+	_, _ = io.WriteString(e.out, sb.String())
+	e.render()
+}
+
+// ReadLine reads a line, returning io.EOF for ctrl-d on an empty line or at the end of the input.
+// the line is not added to the history, so the caller can choose which lines are kept.
+func (e *LineEditor) ReadLine() (string, error) {
+	e.lock.Lock()
+	e.buf, e.pos, e.reading, e.cursorRow, e.search = nil, 0, true, 0, nil
+	e.lines, e.linesRead, e.draft = nil, false, nil
+	e.render()
+	e.lock.Unlock()
+
+	for {
+		r, err := e.readKey()
+		if err != nil {
+			e.done()
+			return "", err
+		}
+		if line, done, err := e.handleKey(r); done {
+			return line, err
+		}
+	}
+}
+
+// done moves past the line, output is written directly until the next line is read.
+func (e *LineEditor) done() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.search = nil
+	e.pos = len(e.buf)
+	e.render()
+	_, _ = io.WriteString(e.out, "\n")
+	e.reading, e.cursorRow = false, 0
+}
+
+// handleKey edits the line with the key, returning the line when it is entered.
+func (e *LineEditor) handleKey(r rune) (string, bool, error) {
+	e.lock.Lock()
+	if e.search != nil && e.searchKey(r) {
+		e.render()
+		e.lock.Unlock()
+		return "", false, nil
+	}
+	switch r {
+	case keyEnter, ctrlJ:
+		line := string(e.buf)
+		e.lock.Unlock()
+		e.done()
+		return line, true, nil
+	case ctrlD:
+		if len(e.buf) == 0 {
+			e.lock.Unlock()
+			e.done()
+			return "", true, io.EOF
+		}
+		e.deleteRunes(e.pos, e.pos+1)
+	case keyDelete:
+		e.deleteRunes(e.pos, e.pos+1)
+	case keyBackspace, ctrlH:
+		if e.pos > 0 {
+			e.deleteRunes(e.pos-1, e.pos)
+		}
+	case ctrlA, keyHome:
+		e.pos = 0
+	case ctrlE, keyEnd:
+		e.pos = len(e.buf)
+	case ctrlB, keyLeft:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case ctrlF, keyRight:
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case keyWordLeft:
+		e.pos = e.wordStart()
+	case keyWordRight:
+		e.pos = e.wordEnd()
+	case ctrlK:
+		e.deleteRunes(e.pos, len(e.buf))
+	case ctrlU:
+		e.deleteRunes(0, e.pos)
+	case ctrlW:
+		e.deleteRunes(e.wordStart(), e.pos)
+	case ctrlP, keyUp:
+		e.recall(-1)
+	case ctrlN, keyDown:
+		e.recall(1)
+	case ctrlR:
+		e.readLines()
+		e.search = &lineSearch{match: -1, saved: append([]rune(nil), e.buf...), savedPos: e.pos}
+	case ctrlL:
+		_, _ = io.WriteString(e.out, "\033[H\033[2J")
+		e.cursorRow = 0
+	case keyTab:
+		e.complete()
+	default:
+		if r >= ' ' && r != keyBackspace && r != utf8.RuneError {
+			e.insert(string(r))
+		}
+	}
+	e.render()
+	e.lock.Unlock()
+	return "", false, nil
+}
+
+func (e *LineEditor) insert(s string) {
+	text := []rune(s)
+	buf := make([]rune, 0, len(e.buf)+len(text))
+	buf = append(append(append(buf, e.buf[:e.pos]...), text...), e.buf[e.pos:]...)
+	e.buf, e.pos = buf, e.pos+len(text)
+}
+
+func (e *LineEditor) deleteRunes(from, to int) {
+	if to > len(e.buf) {
+		to = len(e.buf)
+	}
+	if from >= to {
+		return
+	}
+	e.buf = append(e.buf[:from:from], e.buf[to:]...)
+	e.pos = from
+}
+
+// wordStart returns the start of the word before the cursor.
+func (e *LineEditor) wordStart() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (e *LineEditor) wordEnd() int {
+	i := e.pos
+	for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	return i
+}
+
+// readLines reads the history for the line, late so a line added while this one was started is included.
+func (e *LineEditor) readLines() {
+	if !e.linesRead {
+		e.lines, e.linesRead = e.history.Lines(), true
+		e.lineIdx = len(e.lines)
+	}
+}
+
+// recall replaces the line with the previous (-1) or next (1) line in the history.
+func (e *LineEditor) recall(dir int) {
+	e.readLines()
+	idx := e.lineIdx + dir
+	if idx < 0 || idx > len(e.lines) {
+		return
+	}
+	if e.lineIdx == len(e.lines) {
+		e.draft = append([]rune(nil), e.buf...)
+	}
+	e.lineIdx = idx
+	if idx == len(e.lines) {
+		e.buf = e.draft
+	} else {
+		e.buf = []rune(e.lines[idx])
+	}
+	e.pos = len(e.buf)
+}
+
+// searchKey handles a key during a reverse search, returning false when the key ends the search
+// and is to be handled as usual, with the match as the line.
+func (e *LineEditor) searchKey(r rune) bool {
+	s := e.search
+	switch {
+	case r == ctrlR:
+		from := len(e.lines) - 1
+		if s.match >= 0 {
+			from = s.match - 1
+		}
+		e.find(from)
+	case r == keyBackspace || r == ctrlH:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			e.find(len(e.lines) - 1)
+		}
+	case r == ctrlG || r == keyEscape:
+		e.buf, e.pos, e.search = s.saved, s.savedPos, nil
+	case r >= ' ' && r != utf8.RuneError:
+		s.query = append(s.query, r)
+		from := len(e.lines) - 1
+		if s.match >= 0 {
+			from = s.match
+		}
+		e.find(from)
+	default:
+		if s.match >= 0 {
+			e.buf = []rune(e.lines[s.match])
+			e.lineIdx = s.match
+		} else {
+			e.buf = s.saved
+		}
+		e.pos = len(e.buf)
+		e.search = nil
+		return false
+	}
+	return true
+}
+
+// find searches the history for the query, from the line at index from back to the oldest.
+// when nothing matches, the last match is kept.
+func (e *LineEditor) find(from int) {
+	s := e.search
+	query := string(s.query)
+	for i := from; i >= 0; i-- {
+		if strings.Contains(e.lines[i], query) {
+			s.match, s.failed = i, false
+			return
+		}
+	}
+	s.failed = true
+}
+
+// complete completes the word before the cursor, a single candidate is inserted, otherwise the
+// common prefix of the candidates, or when there is none they are listed.
+func (e *LineEditor) complete() {
+	if e.Complete == nil {
+		return
+	}
+	before := string(e.buf[:e.pos])
+	start, candidates := e.Complete(before)
+	if len(candidates) == 0 || start < 0 || start > len(before) {
+		return
+	}
+	word := before[start:]
+	replace := func(s string) {
+		e.deleteRunes(utf8.RuneCountInString(before[:start]), e.pos)
+		e.insert(s)
+	}
+	if len(candidates) == 1 {
+		replace(candidates[0])
+		if e.pos == len(e.buf) || e.buf[e.pos] != ' ' {
+			e.insert(" ")
+		}
+		return
+	}
+	if prefix := commonPrefix(candidates); len(prefix) > len(word) && strings.EqualFold(prefix[:len(word)], word) {
+		replace(prefix)
+		return
+	}
+	var sb strings.Builder
+	e.clear(&sb)
+	sb.WriteString(strings.Join(candidates, "  ") + "\n")
+	_, _ = io.WriteString(e.out, sb.String())
+}
+
+func commonPrefix(list []string) string {
+	prefix := list[0]
+	for _, s := range list[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// readKey reads a character, or a key sent as an escape sequence.
+func (e *LineEditor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEsc {
+		return r, err
+	}
+	if e.in.Buffered() == 0 {
+		return keyEscape, nil // a sequence arrives all at once, so this is the escape key itself
+	}
+	b, err := e.in.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case '[', 'O':
+	default:
+		return keyUnknown, nil
+	}
+	var params []byte
+	for {
+		c, err := e.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c >= 0x40 && c <= 0x7e {
+			return escapeKey(string(params), c), nil
+		}
+		params = append(params, c)
+	}
+}
+
+// escapeKey returns the key of a CSI or SS3 sequence by its parameters and final byte.
+func escapeKey(params string, final byte) rune {
+	switch final {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		return keyRight
+	case 'D':
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyUnknown
+}
+
+// display returns the prompt, line and cursor position to render.
+func (e *LineEditor) display() (string, []rune, int) {
+	s := e.search
+	if s == nil {
+		return e.prompt, e.buf, e.pos
+	}
+	label := "reverse-i-search"
+	if s.failed {
+		label = "failing " + label
+	}
+	prompt := fmt.Sprintf("(%s)`%s': ", label, string(s.query))
+	if s.match < 0 {
+		return prompt, nil, 0
+	}
+	line := e.lines[s.match]
+	idx := strings.Index(line, string(s.query))
+	if idx < 0 {
+		idx = 0
+	}
+	return prompt, []rune(line), utf8.RuneCountInString(line[:idx])
+}
+
+// render redraws the prompt and line, and places the cursor, long lines wrap at the terminal width.
+func (e *LineEditor) render() {
+	prompt, text, cursor := e.display()
+	width := TerminalWidth()
+	var sb strings.Builder
+	e.clear(&sb)
+	sb.WriteString(prompt)
+	sb.WriteString(string(text))
+
+	promptWidth := StringWidth(prompt)
+	end := promptWidth + runesWidth(text)
+	at := promptWidth + runesWidth(text[:cursor])
+	endRow, atRow := end/width, at/width
+	if end > 0 && end%width == 0 {
+		// the cursor waits in the last column until more is written, so move it to the next row
+		sb.WriteString("\n")
+	}
+	if n := endRow - atRow; n > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", n))
+	}
+	sb.WriteString("\r")
+	if col := at % width; col > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dC", col))
+	}
+	e.cursorRow = atRow
+	_, _ = io.WriteString(e.out, sb.String())
+}
+
+// clear moves to the start of the prompt and clears the screen below it.
+func (e *LineEditor) clear(sb *strings.Builder) {
+	if e.cursorRow > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", e.cursorRow))
+	}
+	sb.WriteString("\r\033[J")
+	e.cursorRow = 0
+}
+
+func runesWidth(text []rune) int {
+	n := 0
+	for _, r := range text {
+		n += RuneWidth(r)
+	}
+	return n
+}
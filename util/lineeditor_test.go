@@ -0,0 +1,196 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineEditor_ReadLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []string
+		keys    string
+		want    string
+	}{
+		{"typed", nil, "hello\r", "hello"},
+		{"newline", nil, "hello\n", "hello"},
+		{"unicode", nil, "héllo\x02\x02\x02\x7f\r", "hllo"},
+		{"ctrl-b", nil, "helo\x02l\r", "hello"},
+		{"arrows", nil, "ac\x1b[Db\x1b[Cd\r", "abcd"},
+		{"home and end", nil, "bc\x1b[Ha\x1b[Fd\r", "abcd"},
+		{"home and end tilde", nil, "bc\x1b[1~a\x1b[4~d\r", "abcd"},
+		{"backspace", nil, "abx\x7fc\r", "abc"},
+		{"delete", nil, "abxc\x1b[D\x1b[D\x1b[3~\r", "abc"},
+		{"ctrl-d deletes", nil, "ab\x02\x04\r", "a"},
+		{"ctrl-u", nil, "junk\x15ok\r", "ok"},
+		{"ctrl-k", nil, "okjunk\x01\x06\x06\x0b\r", "ok"},
+		{"ctrl-w", nil, "like 1 2\x17\r", "like 1 "},
+		{"word left", nil, "one two\x1bbx\r", "one xtwo"},
+		{"word right", nil, "one two\x01\x1bfx\r", "onex two"},
+		{"unknown sequence", nil, "a\x1b[5~b\r", "ab"},
+		{"up", []string{"first", "second"}, "\x1b[A\r", "second"},
+		{"up twice", []string{"first", "second"}, "\x10\x10\r", "first"},
+		{"past the oldest", []string{"first", "second"}, "\x1b[A\x1b[A\x1b[A\r", "first"},
+		{"back to the draft", []string{"first", "second"}, "dr\x1b[A\x1b[A\x1b[B\x0e\r", "dr"},
+		{"edit recalled", []string{"like 1"}, "\x1b[A\x7f2\r", "like 2"},
+		{"search", []string{"find go", "like 1", "find rust"}, "\x12find\r", "find rust"},
+		{"search older", []string{"find go", "like 1", "find rust"}, "\x12find\x12\r", "find go"},
+		{"search backspace", []string{"find go", "like 1", "find rust"}, "\x12lx\x7f\r", "like 1"},
+		{"search fails", []string{"find go"}, "x\x12zz\r", "x"},
+		{"search keeps last match", []string{"find go"}, "\x12goo\r", "find go"},
+		{"search cancel", []string{"find go"}, "x\x12fi\x07\r", "x"},
+		{"search accept and edit", []string{"find go", "like 1"}, "\x12lik\x1b[C!\r", "like 1!"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			history := NewLineHistory("", 0)
+			for _, line := range test.history {
+				assert.NoError(t, history.Add(line))
+			}
+			e := NewLineEditor(strings.NewReader(test.keys), io.Discard, history)
+			line, err := e.ReadLine()
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, line)
+		})
+	}
+}
+
+func TestLineEditor_ReadLine_EOF(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+	}{
+		{"ctrl-d", "\x04"},
+		{"ctrl-d after deleting", "a\x7f\x04"},
+		{"end of input", "abc"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := NewLineEditor(strings.NewReader(test.keys), io.Discard, nil)
+			_, err := e.ReadLine()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestLineEditor_ReadLine_Lines(t *testing.T) {
+	e := NewLineEditor(strings.NewReader("one\rtwo\r"), io.Discard, nil)
+	for _, want := range []string{"one", "two"} {
+		line, err := e.ReadLine()
+		assert.NoError(t, err)
+		assert.Equal(t, want, line)
+	}
+}
+
+func TestLineEditor_Complete(t *testing.T) {
+	words := []string{"like", "likes", "likers", "home"}
+	complete := func(line string) (int, []string) {
+		start := strings.LastIndex(line, " ") + 1
+		var candidates []string
+		for _, w := range words {
+			if strings.HasPrefix(w, line[start:]) {
+				candidates = append(candidates, w)
+			}
+		}
+		return start, candidates
+	}
+
+	tests := []struct {
+		name   string
+		keys   string
+		want   string
+		output string
+	}{
+		{"single", "ho\t\r", "home ", ""},
+		{"common prefix", "lik\t\r", "like", ""},
+		{"list", "like\t\r", "like", "like  likes  likers\n"},
+		{"no candidates", "zz\t\r", "zz", ""},
+		{"before text", "ho x\x02\x02\t\r", "home x", ""},
+		{"second word", "x h\t\r", "x home ", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			e := NewLineEditor(strings.NewReader(test.keys), out, nil)
+			e.Complete = complete
+			line, err := e.ReadLine()
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, line)
+			if test.output != "" {
+				assert.Contains(t, out.String(), test.output)
+			}
+		})
+	}
+}
+
+func TestLineEditor_Print(t *testing.T) {
+	out := new(bytes.Buffer)
+	e := NewLineEditor(strings.NewReader(""), out, nil)
+	e.Print("before\n")
+	assert.Equal(t, "before\n", out.String())
+
+	out.Reset()
+	e.prompt, e.buf, e.pos, e.reading = "> ", []rune("abc"), 1, true
+	e.Print("tweet")
+	assert.Equal(t, "\r\033[Jtweet\n\r\033[J> abc\r\033[3C", out.String())
+
+	out.Reset()
+	e.SetPrompt("$ ")
+	assert.Equal(t, "\r\033[J$ abc\r\033[3C", out.String())
+}
+
+func TestLineEditor_Render(t *testing.T) {
+	origWidth := TerminalWidth()
+	t.Cleanup(func() { terminalWidth = int32(origWidth) })
+	terminalWidth = 10
+
+	tests := []struct {
+		name string
+		line string
+		pos  int
+		want string
+		row  int
+	}{
+		{"short", "abc", 3, "\r\033[J> abc\r\033[5C", 0},
+		{"wrapped", "abcdefghijkl", 12, "\r\033[J> abcdefghijkl\r\033[4C", 1},
+		{"wrapped cursor on first row", "abcdefghijkl", 2, "\r\033[J> abcdefghijkl\033[1A\r\033[4C", 0},
+		{"full row", "abcdefgh", 8, "\r\033[J> abcdefgh\n\r", 1},
+		{"wide", "世界世界世", 5, "\r\033[J> 世界世界世\r\033[2C", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			e := NewLineEditor(strings.NewReader(""), out, nil)
+			e.prompt, e.buf, e.pos = "> ", []rune(test.line), test.pos
+			e.render()
+			assert.Equal(t, test.want, out.String())
+			assert.Equal(t, test.row, e.cursorRow)
+
+			// the next render starts from the prompt
+			out.Reset()
+			e.render()
+			if test.row > 0 {
+				assert.True(t, strings.HasPrefix(out.String(), "\033[1A\r\033[J"), out.String())
+			}
+		})
+	}
+}
+
+func TestLineEditor_SearchDisplay(t *testing.T) {
+	out := new(bytes.Buffer)
+	history := NewLineHistory("", 0)
+	assert.NoError(t, history.Add("find golang"))
+	e := NewLineEditor(strings.NewReader("\x12lang"), out, history)
+	_, err := e.ReadLine()
+	assert.Equal(t, io.EOF, err)
+	assert.Contains(t, out.String(), "(reverse-i-search)`lang': find golang\r\033[33C")
+
+	out.Reset()
+	e = NewLineEditor(strings.NewReader("\x12x"), out, history)
+	_, _ = e.ReadLine()
+	assert.Contains(t, out.String(), "(failing reverse-i-search)`x': ")
+}
@@ -0,0 +1,112 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultLineHistorySize is the number of lines kept in a line history.
+const DefaultLineHistorySize = 1000
+
+// LineHistory is the bounded history of the lines entered in the line editor, persisted to a file.
+// lines are appended to the file as they are added, and the file is rewritten to the most
+// recent lines once it grows to twice the size.
+type LineHistory struct {
+	path      string
+	size      int
+	lines     []string // oldest first
+	fileLines int      // the number of lines in the file
+	lock      sync.Mutex
+}
+
+// NewLineHistory creates a history of up to size lines, persisted to the file at path,
+// an empty path keeps the history in memory.
+func NewLineHistory(path string, size int) *LineHistory {
+	if size < 1 {
+		size = DefaultLineHistorySize
+	}
+	return &LineHistory{path: path, size: size}
+}
+
+// Load reads the history file, a missing file is an empty history.
+func (h *LineHistory) Load() error {
+	if h.path == "" {
+		return nil
+	}
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.lines, h.fileLines = nil, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.fileLines++
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			h.lines = append(h.lines, line)
+		}
+	}
+	if len(h.lines) > h.size {
+		h.lines = h.lines[len(h.lines)-h.size:]
+	}
+	return scanner.Err()
+}
+
+// Add appends the line to the history, blank lines and repeats of the last line are skipped.
+func (h *LineHistory) Add(line string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if strings.TrimSpace(line) == "" || strings.ContainsAny(line, "\r\n") ||
+		(len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return nil
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > h.size {
+		h.lines = h.lines[len(h.lines)-h.size:]
+	}
+	if h.path == "" {
+		return nil
+	}
+	if h.fileLines >= 2*h.size {
+		return h.rewrite()
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return err
+	}
+	h.fileLines++
+	return nil
+}
+
+// rewrite replaces the file with the lines in the history.
+func (h *LineHistory) rewrite() error {
+	tmp := h.path + ".tmp"
+	data := strings.Join(h.lines, "\n") + "\n"
+	if err := os.WriteFile(tmp, []byte(data), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	h.fileLines = len(h.lines)
+	return nil
+}
+
+// Lines returns a copy of the history, oldest first.
+func (h *LineHistory) Lines() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]string(nil), h.lines...)
+}
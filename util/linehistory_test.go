@@ -0,0 +1,53 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".history")
+	h := NewLineHistory(path, 3)
+	assert.NoError(t, h.Load(), "a missing file is an empty history")
+	assert.Empty(t, h.Lines())
+
+	for _, line := range []string{"one", "", "  ", "two", "two", "one", "multi\nline"} {
+		assert.NoError(t, h.Add(line))
+	}
+	assert.Equal(t, []string{"one", "two", "one"}, h.Lines())
+
+	loaded := NewLineHistory(path, 3)
+	assert.NoError(t, loaded.Load())
+	assert.Equal(t, h.Lines(), loaded.Lines())
+
+	// the oldest lines are dropped, and the file is rewritten once it holds twice the size
+	for _, line := range []string{"three", "four", "five", "six"} {
+		assert.NoError(t, h.Add(line))
+	}
+	assert.Equal(t, []string{"four", "five", "six"}, h.Lines())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "four\nfive\nsix\n", string(data))
+
+	assert.NoError(t, loaded.Load())
+	assert.Equal(t, []string{"four", "five", "six"}, loaded.Lines())
+}
+
+func TestLineHistory_LoadLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".history")
+	assert.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{"a", "b", "", "c", "d"}, "\n")), 0600))
+	h := NewLineHistory(path, 2)
+	assert.NoError(t, h.Load())
+	assert.Equal(t, []string{"c", "d"}, h.Lines())
+}
+
+func TestLineHistory_Memory(t *testing.T) {
+	h := NewLineHistory("", 0)
+	assert.NoError(t, h.Load())
+	assert.NoError(t, h.Add("like 1"))
+	assert.Equal(t, []string{"like 1"}, h.Lines())
+}
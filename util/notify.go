@@ -0,0 +1,33 @@
+package util
+
+// Notifier is the desktop notification interface
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// DesktopNotifier is the default notifier, which shows notifications with the platform's command line tool
+var DesktopNotifier Notifier = commandNotifier{}
+
+type commandNotifier struct{}
+
+// Notify shows a desktop notification.
+// supports linux with notify-send, and darwin.
+func (n commandNotifier) Notify(title, message string) error {
+	var name string
+	var args []string
+	switch goos {
+	case "linux":
+		name, args = "notify-send", []string{title, message}
+	case "darwin":
+		// the title and message are passed as arguments, so they are never interpreted as script
+		name, args = "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message,
+		}
+	default:
+		return errUnsupportedPlatform
+	}
+	return startCommand(name, args...)
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesktopNotifier_Notify(t *testing.T) {
+	goosSave, startSave := goos, startCommand
+	defer func() { goos, startCommand = goosSave, startSave }()
+
+	tests := []struct {
+		name     string
+		os       string
+		wantName string
+		wantArgs []string
+		wantErr  error
+	}{
+		{"linux", "linux", "notify-send", []string{"title", `it's "quoted"`}, nil},
+		{"darwin", "darwin", "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			"title", `it's "quoted"`,
+		}, nil},
+		{"unsupported platform", "windows", "", nil, errUnsupportedPlatform},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			goos = test.os
+			var gotName string
+			var gotArgs []string
+			startCommand = func(name string, args ...string) error {
+				gotName, gotArgs = name, args
+				return nil
+			}
+			err := DesktopNotifier.Notify("title", `it's "quoted"`)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantName, gotName)
+			assert.Equal(t, test.wantArgs, gotArgs)
+		})
+	}
+}
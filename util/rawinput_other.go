@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package util
+
+import "os"
+
+// RawInput is not supported, so input is read a line at a time.
+func RawInput(f *os.File) (restore func() error, err error) {
+	return nil, errUnsupportedPlatform
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"strings"
+)
+
+// Word is a word of a command line, Start and End are the offsets of its raw text in the line.
+type Word struct {
+	Text       string
+	Start, End int
+}
+
+// SplitWords splits a command line into words like a shell, words are separated by spaces and tabs,
+// and a single or double quote at the start of a word quotes up to the matching quote,
+// eg: `tweet --poll "yes|no way" it's 'a "great" day'`.
+// a backslash is literal, so regular expressions and windows paths are kept as typed, except that
+// in a double quoted string it escapes a double quote or backslash.
+// an unclosed quote is kept as is, so an apostrophe in free text needs no escaping.
+func SplitWords(line string) []Word {
+	var words []Word
+	var sb strings.Builder
+	start := -1
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == ' ' || c == '\t' {
+			if start >= 0 {
+				words = append(words, Word{Text: sb.String(), Start: start, End: i})
+				sb.Reset()
+				start = -1
+			}
+			continue
+		}
+		atStart := start < 0
+		if atStart {
+			start = i
+		}
+		switch {
+		case (c == '\'' || c == '"') && atStart:
+			end := closingQuote(line, i)
+			if end < 0 {
+				sb.WriteByte(c)
+				continue
+			}
+			quoted := line[i+1 : end]
+			if c == '"' {
+				quoted = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(quoted)
+			}
+			sb.WriteString(quoted)
+			i = end
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if start >= 0 {
+		words = append(words, Word{Text: sb.String(), Start: start, End: len(line)})
+	}
+	return words
+}
+
+// closingQuote returns the index of the quote that closes the one at line[open], or -1 if it is unclosed.
+func closingQuote(line string, open int) int {
+	q := line[open]
+	for i := open + 1; i < len(line); i++ {
+		switch {
+		case q == '"' && line[i] == '\\':
+			i++
+		case line[i] == q:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"words", "help test", []string{"help", "test"}},
+		{"single word", "oneword", []string{"oneword"}},
+		{"case kept", "ONE TWO", []string{"ONE", "TWO"}},
+		{"extra spaces", "  like \t 1   2 ", []string{"like", "1", "2"}},
+		{"double quotes", `find "two words" more`, []string{"find", "two words", "more"}},
+		{"single quotes", `find 'say "hi"'`, []string{"find", `say "hi"`}},
+		{"escaped quotes", `tweet "a \"quote\" \\o/"`, []string{"tweet", `a "quote" \o/`}},
+		{"backslashes are literal", `filter add regex \d+ C:\path \"c`, []string{"filter", "add", "regex", `\d+`, `C:\path`, `\"c`}},
+		{"backslash in double quotes", `"C:\my path\\"`, []string{`C:\my path\`}},
+		{"single quotes are literal", `'a\b'`, []string{`a\b`}},
+		{"joined", `--poll="a b"c`, []string{`--poll="a`, `b"c`}},
+		{"quote after start", `"a b"c d`, []string{"a bc", "d"}},
+		{"apostrophe", "tweet it's great", []string{"tweet", "it's", "great"}},
+		{"unclosed quote", `tweet 'tis "the season`, []string{"tweet", "'tis", `"the`, "season"}},
+		{"empty quotes", `a "" b`, []string{"a", "", "b"}},
+		{"trailing backslash", `a\`, []string{`a\`}},
+		{"empty", "", nil},
+		{"blank", "   ", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, w := range SplitWords(test.input) {
+				got = append(got, w.Text)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSplitWords_Offsets(t *testing.T) {
+	line := `reply 1  "hi there"  you`
+	words := SplitWords(line)
+	if !assert.Len(t, words, 4) {
+		return
+	}
+	assert.Equal(t, Word{Text: "hi there", Start: 9, End: 19}, words[2])
+	assert.Equal(t, `"hi there"  you`, line[words[2].Start:words[3].End])
+}
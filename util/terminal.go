@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+)
+
+// DefaultTerminalWidth is the width used when it cannot be detected.
+const DefaultTerminalWidth = 80
+
+var terminalWidth int32 = DefaultTerminalWidth
+
+// test point
+var terminalSize = fileTerminalWidth
+
+// TerminalWidth returns the width of the terminal in columns, kept up to date by WatchTerminalWidth.
+func TerminalWidth() int {
+	return int(atomic.LoadInt32(&terminalWidth))
+}
+
+// DetectTerminalWidth returns the width of stdout when it is a terminal, otherwise the COLUMNS
+// environment variable, falling back to DefaultTerminalWidth.
+func DetectTerminalWidth() int {
+	if width, err := terminalSize(os.Stdout); err == nil && width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return DefaultTerminalWidth
+}
+
+// WatchTerminalWidth detects the terminal width, and updates it when the terminal is resized,
+// until the context is done.
+func WatchTerminalWidth(ctx context.Context) {
+	atomic.StoreInt32(&terminalWidth, int32(DetectTerminalWidth()))
+	if len(resizeSignals) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	notifier(ch, resizeSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				atomic.StoreInt32(&terminalWidth, int32(DetectTerminalWidth()))
+			}
+		}
+	}()
+}
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package util
+
+import "os"
+
+// there is no resize signal, so the width is only detected at start
+var resizeSignals []os.Signal
+
+// the terminal size is not supported, so the width comes from COLUMNS or the default.
+func fileTerminalWidth(f *os.File) (int, error) {
+	return 0, errUnsupportedPlatform
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withTerminalSize(t *testing.T, width int, err error) {
+	t.Helper()
+	orig := terminalSize
+	terminalSize = func(*os.File) (int, error) { return width, err }
+	t.Cleanup(func() { terminalSize = orig })
+}
+
+func TestDetectTerminalWidth(t *testing.T) {
+	errNotTerminal := errors.New("not a terminal")
+	tests := []struct {
+		name    string
+		width   int
+		err     error
+		columns string
+		want    int
+	}{
+		{"terminal", 120, nil, "100", 120},
+		{"columns", 0, errNotTerminal, "100", 100},
+		{"invalid columns", 0, errNotTerminal, "wide", DefaultTerminalWidth},
+		{"default", 0, errNotTerminal, "", DefaultTerminalWidth},
+		{"zero width terminal", 0, nil, "", DefaultTerminalWidth},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withTerminalSize(t, test.width, test.err)
+			t.Setenv("COLUMNS", test.columns)
+			assert.Equal(t, test.want, DetectTerminalWidth())
+		})
+	}
+}
+
+func TestWatchTerminalWidth(t *testing.T) {
+	if len(resizeSignals) == 0 {
+		t.Skip("terminal resize signals are not supported")
+	}
+	origNotifier, origWidth := notifier, TerminalWidth()
+	t.Cleanup(func() {
+		notifier = origNotifier
+		terminalWidth = int32(origWidth)
+	})
+	resizeCh := make(chan chan<- os.Signal, 1)
+	notifier = func(c chan<- os.Signal, sig ...os.Signal) {
+		assert.Equal(t, resizeSignals, sig)
+		resizeCh <- c
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	withTerminalSize(t, 100, nil)
+	WatchTerminalWidth(ctx)
+	assert.Equal(t, 100, TerminalWidth())
+
+	withTerminalSize(t, 60, nil)
+	(<-resizeCh) <- resizeSignals[0]
+	assert.Eventually(t, func() bool { return TerminalWidth() == 60 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package util
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// resizeSignals are sent when the terminal is resized
+var resizeSignals = []os.Signal{syscall.SIGWINCH}
+
+func fileTerminalWidth(f *os.File) (int, error) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, err
+	}
+	return int(ws.Col), nil
+}
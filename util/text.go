@@ -0,0 +1,235 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+
+	"golang.org/x/text/width"
+)
+
+// UTF16Text is text indexed by utf-16 code units, which is how twitter counts entity indices,
+// so characters outside the basic multilingual plane (eg: most emoji) count as 2.
+type UTF16Text struct {
+	units []uint16
+}
+
+// NewUTF16Text creates a new instance from the given string.
+func NewUTF16Text(s string) UTF16Text {
+	return UTF16Text{units: utf16.Encode([]rune(s))}
+}
+
+// Len returns the length of the text in utf-16 code units.
+func (u UTF16Text) Len() int {
+	return len(u.units)
+}
+
+// IsBoundary returns whether the index is within the text and not in the middle of a surrogate pair.
+func (u UTF16Text) IsBoundary(idx int) bool {
+	if idx < 0 || idx > len(u.units) {
+		return false
+	}
+	if idx == 0 || idx == len(u.units) {
+		return true
+	}
+	return !isHighSurrogate(u.units[idx-1]) || !isLowSurrogate(u.units[idx])
+}
+
+func isHighSurrogate(u uint16) bool { return u >= 0xd800 && u < 0xdc00 }
+func isLowSurrogate(u uint16) bool  { return u >= 0xdc00 && u < 0xe000 }
+
+// Slice returns the text between the given utf-16 indices, which must be boundaries.
+func (u UTF16Text) Slice(start, end int) string {
+	return string(utf16.Decode(u.units[start:end]))
+}
+
+// UTF16Len returns the length of the string in utf-16 code units.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2 // surrogate pair
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Truncate shortens the text to at most width characters, ending with '…' when it was shortened.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// Pad fills the text with spaces on the right to the given width in terminal columns,
+// a negative width pads on the left to right align the text.
+func Pad(s string, width int) string {
+	fill := width
+	if fill < 0 {
+		fill = -fill
+	}
+	fill -= StringWidth(s)
+	if fill <= 0 {
+		return s
+	}
+	if width < 0 {
+		return strings.Repeat(" ", fill) + s
+	}
+	return s + strings.Repeat(" ", fill)
+}
+
+// Wrap word wraps the text into lines of at most width terminal columns, see WrapIndent.
+func Wrap(s string, width int) string {
+	return WrapIndent(s, width, "")
+}
+
+// WrapIndent word wraps the text into lines of at most width terminal columns, with a hanging indent,
+// lines after the first are prefixed with the indent, and the first line is assumed to follow a label
+// of the same width, so the text lines up, eg: 'text: {{ .TweetText | wrapindent 0 "      " }}'.
+// lines are only broken where there is already a space, the spaces between words and at the start
+// of a line are kept (eg: code and aligned lists), and the spaces at a break are dropped.
+// escape sequences (colors) take no space, wide characters take 2 columns,
+// and words longer than the width are put on a line of their own.
+func WrapIndent(s string, width int, indent string) string {
+	if width <= 0 {
+		return s
+	}
+	indentWidth := StringWidth(indent)
+	var sb strings.Builder
+	lineWidth, lines, hasWords := indentWidth, 0, false
+	startLine := func() {
+		sb.WriteString("\n")
+		lineWidth, hasWords = indentWidth, false
+		lines++
+	}
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			startLine()
+		}
+		for _, w := range splitWords(line) {
+			gapWidth, wordWidth := StringWidth(w.gap), StringWidth(w.word)
+			if hasWords && lineWidth+gapWidth+wordWidth > width {
+				startLine()
+				w.gap, gapWidth = "", 0
+			}
+			if !hasWords && lines > 0 {
+				sb.WriteString(indent)
+			}
+			sb.WriteString(w.gap)
+			sb.WriteString(w.word)
+			lineWidth += gapWidth + wordWidth
+			hasWords = true
+		}
+	}
+	return sb.String()
+}
+
+// spacedWord is a word and the spaces before it.
+type spacedWord struct {
+	gap, word string
+}
+
+// splitWords splits the line into words with the spaces before each, trailing spaces are dropped.
+func splitWords(line string) []spacedWord {
+	var words []spacedWord
+	for line != "" {
+		start := strings.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) })
+		if start < 0 {
+			break
+		}
+		end := strings.IndexFunc(line[start:], unicode.IsSpace)
+		if end < 0 {
+			end = len(line) - start
+		}
+		words = append(words, spacedWord{gap: line[:start], word: line[start : start+end]})
+		line = line[start+end:]
+	}
+	return words
+}
+
+// ansiEscape matches terminal escape sequences, eg: colors
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// StripANSI removes terminal escape sequences from the text.
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// StringWidth returns the number of terminal columns the text takes, ignoring escape sequences.
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range StripANSI(s) {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// RuneWidth returns the number of terminal columns the rune takes,
+// 2 for wide characters (eg: CJK and emoji), 0 for combining and zero width characters.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0, unicode.IsControl(r),
+		unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf), // combining marks, zero width joiner
+		r >= 0xfe00 && r <= 0xfe0f:                        // variation selectors
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	if r >= 0x1f300 && r <= 0x1faff { // pictographs and emoji
+		return 2
+	}
+	return 1
+}
+
+// HumanizeDuration formats a duration in its largest unit, eg: '42s', '3m', '5h', '2d' or '1y'.
+func HumanizeDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+}
+
+// Humanize formats a count in a short form, eg: 999, 1.2k, 15k, 3.4M
+func Humanize(n int) string {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	units := []struct {
+		size   int
+		suffix string
+	}{{1_000_000_000, "B"}, {1_000_000, "M"}, {1_000, "k"}}
+	for _, u := range units {
+		if abs < u.size {
+			continue
+		}
+		value := float64(n) / float64(u.size)
+		if abs >= 10*u.size {
+			// drop the decimal once there are 2 digits, eg: 15k rather than 15.3k
+			return strconv.Itoa(n/u.size) + u.suffix
+		}
+		// truncate rather than round so 1999 is 1.9k and never 2.0k
+		return strings.TrimSuffix(strconv.FormatFloat(float64(int(value*10))/10, 'f', 1, 64), ".0") + u.suffix
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,195 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUTF16Text(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		length     int
+		boundaries map[int]bool
+	}{
+		{"empty", "", 0, map[int]bool{-1: false, 0: true, 1: false}},
+		{"ascii", "abc", 3, map[int]bool{0: true, 2: true, 3: true, 4: false}},
+		{"cjk", "日本語", 3, map[int]bool{1: true, 2: true, 3: true}},
+		{"emoji", "a👍b", 4, map[int]bool{1: true, 2: false, 3: true, 4: true}},
+		{"adjacent emoji", "👍👍", 4, map[int]bool{1: false, 2: true, 3: false}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			utext := NewUTF16Text(test.text)
+			assert.Equal(t, test.length, utext.Len())
+			assert.Equal(t, test.length, UTF16Len(test.text))
+			assert.Equal(t, test.text, utext.Slice(0, utext.Len()))
+			for idx, want := range test.boundaries {
+				assert.Equal(t, want, utext.IsBoundary(idx), "index %d", idx)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"shorter", "abc", 5, "abc"},
+		{"exact", "abcde", 5, "abcde"},
+		{"longer", "abcdef", 5, "abcd…"},
+		{"multi byte", "日本語のテキスト", 4, "日本語…"},
+		{"zero", "abc", 0, ""},
+		{"negative", "abc", -1, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Truncate(test.text, test.width))
+		})
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"left align", "ab", 4, "ab  "},
+		{"right align", "ab", -4, "  ab"},
+		{"longer", "abcdef", 4, "abcdef"},
+		{"multi byte", "日本", 6, "日本  "},
+		{"wide", "日本", 4, "日本"},
+		{"colored", "\033[31mab\033[0m", 4, "\033[31mab\033[0m  "},
+		{"zero", "ab", 0, "ab"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Pad(test.text, test.width))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"short", "one two", 10, "one two"},
+		{"wraps", "one two three four", 9, "one two\nthree\nfour"},
+		{"exact fit", "one two", 7, "one two"},
+		{"long word", "a supercalifragilistic word", 10, "a\nsupercalifragilistic\nword"},
+		{"keeps newlines", "one two\nthree four", 20, "one two\nthree four"},
+		{"keeps spaces", "one   two", 20, "one   two"},
+		{"keeps indentation", "if x {\n    y()\n}", 20, "if x {\n    y()\n}"},
+		{"aligned columns", "a    1\nbb   22", 20, "a    1\nbb   22"},
+		{"drops spaces at a break", "one   two", 5, "one\ntwo"},
+		{"drops trailing spaces", "one two  ", 20, "one two"},
+		{"no width", "one two", 0, "one two"},
+		{"ignores colors", "\033[31mone\033[0m two three", 9, "\033[31mone\033[0m two\nthree"},
+		{"wide", "日本語 日本語 日本語", 14, "日本語 日本語\n日本語"},
+		{"emoji", "😀😀 😀😀", 8, "😀😀\n😀😀"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Wrap(test.text, test.width))
+		})
+	}
+}
+
+func TestWrapIndent(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		width  int
+		indent string
+		want   string
+	}{
+		{"short", "one two", 10, "  ", "one two"},
+		{"hanging indent", "one two three four", 10, "  ", "one two\n  three\n  four"},
+		{"first line follows label", "one two three", 9, "    ", "one\n    two\n    three"},
+		{"new lines indented", "one\ntwo", 10, "> ", "one\n> two"},
+		{"empty lines not indented", "one\n\ntwo", 10, "> ", "one\n\n> two"},
+		{"indented lines keep their spaces", "one\n  two", 10, "> ", "one\n>   two"},
+		{"colored indent", "one two three", 9, "\033[31m|\033[0m ", "one two\n\033[31m|\033[0m three"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, WrapIndent(test.text, test.width, test.indent))
+		})
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"ascii", "hello", 5},
+		{"empty", "", 0},
+		{"ansi", "\033[1;38;5;208mhello\033[0m", 5},
+		{"cjk", "日本語", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"emoji", "😀 ok", 5},
+		{"combining", "e\u0301", 1},
+		{"zero width joiner", "👩\u200d💻", 4},
+		{"variation selector", "❤\ufe0f", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, StringWidth(test.text))
+		})
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1k"},
+		{1234, "1.2k"},
+		{1999, "1.9k"},
+		{15300, "15k"},
+		{999999, "999k"},
+		{1000000, "1M"},
+		{3450000, "3.4M"},
+		{2500000000, "2.5B"},
+		{-1234, "-1.2k"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, Humanize(test.n))
+		})
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{42 * time.Second, "42s"},
+		{3*time.Minute + 59*time.Second, "3m"},
+		{5 * time.Hour, "5h"},
+		{50 * time.Hour, "2d"},
+		{800 * 24 * time.Hour, "2y"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, HumanizeDuration(test.d))
+		})
+	}
+}
@@ -17,7 +17,18 @@ import (
 var errUnsupportedPlatform = fmt.Errorf("unsupported platform")
 
 var goos = runtime.GOOS
-var startCommand = func(name string, args ...string) error { return exec.Command(name, args...).Start() }
+var startCommand = runDetached
+
+// runDetached starts the command without waiting for it to finish, it is waited on in the background
+// so the process is reaped when it exits.
+func runDetached(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
 
 // OpenBrowser opens the given url in a web browser.
 // supports linux, windows, and darwin.
@@ -83,14 +94,45 @@ func FirstNumber(args ...string) (int, bool) {
 	return 0, false
 }
 
-// SplitCommand takes a string and returns command and arguments
-func SplitCommand(str string) (string, []string) {
-	str = strings.TrimSpace(str)
-	split := strings.Split(str, " ")
-	if len(split) > 1 {
-		return strings.ToLower(split[0]), split[1:]
+// MaxNumberList is the most numbers a list can hold, so a typo like '1-1000' is caught
+const MaxNumberList = 100
+
+// ParseNumberList parses a comma separated list of numbers and inclusive ranges eg: '3,5,7-10',
+// in the order given without duplicates.
+func ParseNumberList(s string) ([]int, error) {
+	var nums []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi := part, part
+		if idx := strings.Index(part, "-"); idx >= 0 {
+			lo, hi = part[:idx], part[idx+1:]
+		}
+		start, err := strconv.Atoi(lo)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		end, err := strconv.Atoi(hi)
+		if err != nil || end < start {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+		for n := start; n <= end; n++ {
+			if seen[n] {
+				continue
+			}
+			if len(nums) == MaxNumberList {
+				return nil, fmt.Errorf("too many numbers in %q, at most %d", s, MaxNumberList)
+			}
+			seen[n] = true
+			nums = append(nums, n)
+		}
+	}
+	if len(nums) == 0 {
+		return nil, fmt.Errorf("invalid number list %q", s)
 	}
-	return strings.ToLower(str), nil
+	return nums, nil
 }
 
 // MustString panic on error
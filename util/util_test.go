@@ -3,7 +3,6 @@ package util
 import (
 	"fmt"
 	"os"
-	"reflect"
 	"strings"
 	"syscall"
 	"testing"
@@ -57,25 +56,38 @@ func TestFirstNumber(t *testing.T) {
 	}
 }
 
-func TestSplitCommand(t *testing.T) {
+func TestParseNumberList(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		cmd   string
-		args  []string
+		name    string
+		s       string
+		want    []int
+		wantErr string
 	}{
-		{"happy", "help test", "help", []string{"test"}},
-		{"single word", "oneword", "oneword", nil},
-		{"single word uppercase", "ONEWORD", "oneword", nil},                       // BUG #18
-		{"single all uppercase", "ONE TWO THREE", "one", []string{"TWO", "THREE"}}, // BUG #18
-		{"empty", "", "", nil},
+		{"single", "3", []int{3}, ""},
+		{"list", "3,5", []int{3, 5}, ""},
+		{"range", "7-10", []int{7, 8, 9, 10}, ""},
+		{"mixed", "3,5,7-10", []int{3, 5, 7, 8, 9, 10}, ""},
+		{"order kept", "9,1-2", []int{9, 1, 2}, ""},
+		{"duplicates", "1-3,2,3-4", []int{1, 2, 3, 4}, ""},
+		{"empty parts", "1,,2,", []int{1, 2}, ""},
+		{"single range", "4-4", []int{4}, ""},
+		{"empty", "", nil, `invalid number list ""`},
+		{"commas", ",", nil, `invalid number list ","`},
+		{"word", "3,a", nil, `invalid number "a"`},
+		{"negative", "-1", nil, `invalid number "-1"`},
+		{"reversed", "10-7", nil, `invalid range "10-7"`},
+		{"open range", "7-", nil, `invalid range "7-"`},
+		{"too many", "1-101", nil, `too many numbers in "1-101", at most 100`},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cmd, args := SplitCommand(test.input)
-			if cmd != test.cmd || !reflect.DeepEqual(args, test.args) {
-				t.Fail()
+			got, err := ParseNumberList(test.s)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
 			}
+			assert.Equal(t, test.want, got)
 		})
 	}
 }
@@ -187,6 +199,23 @@ func TestOpenBrowser(t *testing.T) {
 	}
 }
 
+func TestRunDetached(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{"started", "true", false},
+		{"not found", "tweetstreem-no-such-command", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := runDetached(test.command)
+			assert.Equal(t, test.wantErr, err != nil)
+		})
+	}
+}
+
 func TestMustString(t *testing.T) {
 	t.Run("panic if err", func(t *testing.T) {
 		assert.Panics(t, func() {